@@ -0,0 +1,428 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cixtor/slackapi"
+)
+
+// BatchResult is the outcome of a command executed in batch mode. Result has
+// the JSON printed by the command and Error the JSON of the failure, if any.
+type BatchResult struct {
+	Line     int             `json:"line"`
+	Command  string          `json:"command"`
+	Ok       bool            `json:"ok"`
+	ExitCode int             `json:"exit_code"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	Error    json.RawMessage `json:"error,omitempty"`
+}
+
+// BatchSummary is printed after the results of a batch.
+type BatchSummary struct {
+	Ok        bool `json:"ok"`
+	Total     int  `json:"total"`
+	Succeeded int  `json:"succeeded"`
+	Failed    int  `json:"failed"`
+}
+
+// batchJob is a command read from the input of a batch.
+type batchJob struct {
+	line int
+	text string
+	args []string
+	err  error
+}
+
+// batchDone is the result of the job at position n of a batch.
+type batchDone struct {
+	n      int
+	result BatchResult
+}
+
+// batchWindow is the number of results per worker that can wait to be
+// printed while an earlier command is still running.
+const batchWindow = 4
+
+// batchCommand is a command written as a JSON object, like this:
+//
+//	{"method":"chat.delete","args":{"channel":"C123","time":"1503435956.000247"}}
+//	{"method":"chat.delete","args":["C123","1503435956.000247"]}
+type batchCommand struct {
+	Method string          `json:"method"`
+	Args   json.RawMessage `json:"args"`
+}
+
+// CallBatch executes the commands from a file, or the standard input, one per
+// line. The commands are written like in the command line, without the name
+// of the program, or as JSON objects with the method and its arguments. The
+// commands share the credentials and the rate limiter, and run in parallel
+// up to the given concurrency. Each result is printed as a JSON object in the
+// order of the input, as soon as the commands before it are done, followed by
+// a summary.
+func (cli *CLI) CallBatch() int {
+	input := cli.stdin
+
+	if filename := cli.String("file"); filename != "" && filename != "-" {
+		file, err := os.Open(filename)
+
+		if err != nil {
+			return cli.PrintError(fmt.Errorf("batch; %s", err))
+		}
+
+		defer file.Close()
+
+		input = file
+	}
+
+	jobs, err := readBatch(input)
+
+	if err != nil {
+		return cli.PrintError(fmt.Errorf("batch; %s", err))
+	}
+
+	concurrency := cli.Number("concurrency")
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	if concurrency > 1 {
+		// The workers share the cache, so it is loaded once instead of by
+		// each one of them. If it fails, the commands report the error.
+		cli.loadCache()
+	}
+
+	var wg sync.WaitGroup
+
+	queue := make(chan int)
+	done := make(chan batchDone)
+	slots := make(chan struct{}, concurrency*batchWindow)
+
+	go func() {
+		for n := range jobs {
+			slots <- struct{}{}
+			queue <- n
+		}
+
+		close(queue)
+	}()
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for n := range queue {
+				done <- batchDone{n: n, result: cli.runBatchJob(jobs[n])}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	summary := BatchSummary{Ok: true}
+	exitCode := ExitSuccess
+	encoder := json.NewEncoder(cli.stdout)
+	pending := map[int]BatchResult{}
+	next := 0
+
+	for item := range done {
+		pending[item.n] = item.result
+
+		for {
+			result, ok := pending[next]
+
+			if !ok {
+				break
+			}
+
+			delete(pending, next)
+			next++
+			<-slots
+
+			encoder.Encode(result)
+			summary.Total++
+
+			if result.Ok {
+				summary.Succeeded++
+				continue
+			}
+
+			summary.Failed++
+			summary.Ok = false
+
+			if exitCode == ExitSuccess {
+				exitCode = result.ExitCode
+			}
+		}
+	}
+
+	encoder.Encode(summary)
+
+	return exitCode
+}
+
+// runBatchJob executes one command of a batch with a copy of the program that
+// writes into buffers, so the commands do not share their parameters nor
+// mix their output. The copy has its own client of the web API service,
+// because commands like auth.login change its token. The commands are
+// registered again because they are bound to the instance of CLI that
+// registered them.
+func (cli *CLI) runBatchJob(job batchJob) BatchResult {
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+
+	worker := *cli
+	worker.api = slackapi.New()
+	worker.api.SetToken(cli.profile.Token)
+	worker.api.SetCookie(cli.profile.Cookie)
+	worker.api.SetDebug(cli.debug)
+	worker.command = ""
+	worker.params = nil
+	worker.commands = nil
+	worker.index = nil
+	worker.namespaces = nil
+	worker.stdin = nil
+	worker.stdout = &stdout
+	worker.stderr = &stderr
+	worker.output = "json"
+	worker.template = nil
+
+	registerCommands(&worker)
+
+	result := BatchResult{Line: job.line, Command: strings.Join(job.args, "\x20")}
+
+	switch {
+	case job.err != nil:
+		result.Command = job.text
+		result.ExitCode = worker.PrintError(UsageError("batch; line %d; %s", job.line, job.err))
+
+	case job.args[0] == "batch" || job.args[0] == "shell":
+		result.ExitCode = worker.PrintError(UsageError("batch; line %d; %s cannot run in a batch", job.line, job.args[0]))
+
+	default:
+		result.ExitCode = worker.Execute(job.args)
+	}
+
+	result.Result = rawOutput(stdout.Bytes())
+	result.Error = rawOutput(stderr.Bytes())
+	result.Ok = result.ExitCode == ExitSuccess && !reportsFailure(result.Result)
+
+	if !result.Ok && result.ExitCode == ExitSuccess {
+		result.ExitCode = ExitFailure
+	}
+
+	return result
+}
+
+// reportsFailure returns true if the output of a command is a response of the
+// web API service with "ok": false, even if the command did not fail.
+func reportsFailure(out json.RawMessage) bool {
+	var res struct {
+		Ok *bool `json:"ok"`
+	}
+
+	return json.Unmarshal(out, &res) == nil && res.Ok != nil && !*res.Ok
+}
+
+// rawOutput returns the output of a command as compact JSON, or as a JSON
+// string if the command did not print a single JSON document.
+func rawOutput(out []byte) json.RawMessage {
+	var buf bytes.Buffer
+
+	out = bytes.TrimSpace(out)
+
+	if len(out) == 0 {
+		return nil
+	}
+
+	if json.Compact(&buf, out) == nil {
+		return buf.Bytes()
+	}
+
+	text, _ := json.Marshal(string(out))
+
+	return text
+}
+
+// readBatch reads the commands of a batch. Empty lines and lines starting
+// with a hash are ignored. A line that cannot be parsed is kept as a job with
+// an error, so it is reported with the rest of the results.
+func readBatch(r io.Reader) ([]batchJob, error) {
+	var jobs []batchJob
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		job := batchJob{line: line, text: text}
+
+		if strings.HasPrefix(text, "{") {
+			job.args, job.err = parseBatchObject(text)
+		} else {
+			job.args, job.err = splitWords(text)
+		}
+
+		if job.err == nil && len(job.args) == 0 {
+			job.err = fmt.Errorf("missing command")
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, scanner.Err()
+}
+
+// parseBatchObject converts a command written as a JSON object into the
+// arguments of the command line. Arguments in an object are passed by name,
+// arguments in an array are passed by position.
+func parseBatchObject(text string) ([]string, error) {
+	var command batchCommand
+
+	if err := json.Unmarshal([]byte(text), &command); err != nil {
+		return nil, err
+	}
+
+	if command.Method == "" {
+		return nil, fmt.Errorf("missing method")
+	}
+
+	args := []string{command.Method}
+
+	if len(command.Args) == 0 || string(command.Args) == "null" {
+		return args, nil
+	}
+
+	var named map[string]json.RawMessage
+
+	if json.Unmarshal(command.Args, &named) == nil {
+		keys := make([]string, 0, len(named))
+
+		for key := range named {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			args = append(args, "--"+key+"="+argumentText(named[key]))
+		}
+
+		return args, nil
+	}
+
+	var positional []json.RawMessage
+
+	if err := json.Unmarshal(command.Args, &positional); err != nil {
+		return nil, fmt.Errorf("args must be an object or an array")
+	}
+
+	for _, value := range positional {
+		args = append(args, argumentText(value))
+	}
+
+	return args, nil
+}
+
+// argumentText returns a JSON value as a command line argument. Strings are
+// used as they are, arrays of strings are joined with commas, and the other
+// values are used as JSON.
+func argumentText(value json.RawMessage) string {
+	var text string
+	var list []string
+
+	if json.Unmarshal(value, &text) == nil {
+		return text
+	}
+
+	if json.Unmarshal(value, &list) == nil {
+		return strings.Join(list, ",")
+	}
+
+	return string(value)
+}
+
+// splitWords splits a line into words like a shell. Words are separated by
+// spaces, single quotes preserve the text as it is, double quotes and the
+// backslash allow spaces and quotes inside a word.
+func splitWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+
+	var quote rune
+	var escaped bool
+	var inWord bool
+
+	for _, char := range line {
+		switch {
+		case escaped:
+			word.WriteRune(char)
+			escaped = false
+
+		case quote == '\'':
+			if char == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(char)
+			}
+
+		case char == '\\':
+			escaped = true
+			inWord = true
+
+		case quote == '"':
+			if char == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(char)
+			}
+
+		case char == '\'' || char == '"':
+			quote = char
+			inWord = true
+
+		case char == '\x20' || char == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+
+		default:
+			word.WriteRune(char)
+			inWord = true
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("unfinished escape sequence")
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+
+	if inWord {
+		words = append(words, word.String())
+	}
+
+	return words, nil
+}
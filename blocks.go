@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxBlocks is the number of blocks accepted in a message.
+const maxBlocks = 50
+
+// maxBlockID is the length limit of the block_id field of every block.
+const maxBlockID = 255
+
+// blockTypes are the types of block accepted in a message, with the number
+// of elements accepted by the blocks that have a list of elements.
+var blockTypes = map[string]int{
+	"actions":   25,
+	"context":   10,
+	"divider":   0,
+	"file":      0,
+	"header":    0,
+	"image":     0,
+	"input":     0,
+	"markdown":  0,
+	"rich_text": 0,
+	"section":   0,
+	"video":     0,
+}
+
+// ParseBlocks decodes and validates the Block Kit layout of a message before
+// it is sent. It accepts a list of blocks or an object with a blocks field,
+// like the payloads exported by the Block Kit Builder, and returns the list
+// of blocks. Errors point at the offending block, like blocks[2].
+func ParseBlocks(input string) (json.RawMessage, error) {
+	var blocks []interface{}
+
+	data := []byte(strings.TrimSpace(input))
+
+	if bytes.HasPrefix(data, []byte("{")) {
+		var payload struct {
+			Blocks []interface{} `json:"blocks"`
+		}
+
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return nil, fmt.Errorf("blocks: invalid JSON: %s", err)
+		}
+
+		blocks = payload.Blocks
+	} else if err := json.Unmarshal(data, &blocks); err != nil {
+		return nil, fmt.Errorf("blocks: invalid JSON: %s", err)
+	}
+
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("blocks: expected a list with at least one block")
+	}
+
+	if len(blocks) > maxBlocks {
+		return nil, fmt.Errorf("blocks: a message accepts up to %d blocks, got %d", maxBlocks, len(blocks))
+	}
+
+	for i, value := range blocks {
+		block, _ := value.(map[string]interface{})
+
+		if err := validateBlock(block); err != nil {
+			kind, _ := block["type"].(string)
+
+			if kind == "" {
+				return nil, fmt.Errorf("blocks[%d]: %s", i, err)
+			}
+
+			return nil, fmt.Errorf("blocks[%d] (%s): %s", i, kind, err)
+		}
+	}
+
+	return json.Marshal(blocks)
+}
+
+// validateBlock checks the fields of a block that are known to be rejected
+// by the web API service, like missing text or text over the length limits.
+func validateBlock(block map[string]interface{}) error {
+	if block == nil {
+		return fmt.Errorf("expected an object")
+	}
+
+	kind, ok := block["type"].(string)
+
+	if !ok || kind == "" {
+		return fmt.Errorf("missing type")
+	}
+
+	maxElements, ok := blockTypes[kind]
+
+	if !ok {
+		return fmt.Errorf("unknown type %q, expected one of %s", kind, strings.Join(knownBlockTypes(), ", "))
+	}
+
+	if id, ok := block["block_id"].(string); ok && utf8.RuneCountInString(id) > maxBlockID {
+		return fmt.Errorf("block_id is longer than %d characters", maxBlockID)
+	}
+
+	switch kind {
+	case "section":
+		_, hasText := block["text"]
+		fields, hasFields := block["fields"].([]interface{})
+
+		if !hasText && !hasFields {
+			return fmt.Errorf("expected text or fields")
+		}
+
+		if hasText {
+			if err := validateText(block["text"], 3000); err != nil {
+				return fmt.Errorf("text: %s", err)
+			}
+		}
+
+		if len(fields) > 10 {
+			return fmt.Errorf("fields accepts up to 10 items, got %d", len(fields))
+		}
+
+		for i, field := range fields {
+			if err := validateText(field, 2000); err != nil {
+				return fmt.Errorf("fields[%d]: %s", i, err)
+			}
+		}
+
+	case "header":
+		if err := validateText(block["text"], 150); err != nil {
+			return fmt.Errorf("text: %s", err)
+		}
+
+		if text, _ := block["text"].(map[string]interface{}); text["type"] != "plain_text" {
+			return fmt.Errorf("text must be plain_text")
+		}
+
+	case "markdown":
+		text, ok := block["text"].(string)
+
+		if !ok || text == "" {
+			return fmt.Errorf("missing text")
+		}
+
+		if utf8.RuneCountInString(text) > 12000 {
+			return fmt.Errorf("text is longer than 12000 characters")
+		}
+
+	case "image":
+		_, hasURL := block["image_url"].(string)
+		_, hasFile := block["slack_file"].(map[string]interface{})
+
+		if !hasURL && !hasFile {
+			return fmt.Errorf("expected image_url or slack_file")
+		}
+
+		if err := validateString(block, "image_url", 3000); err != nil {
+			return err
+		}
+
+		if alt, ok := block["alt_text"].(string); !ok || alt == "" {
+			return fmt.Errorf("missing alt_text")
+		}
+
+		if err := validateString(block, "alt_text", 2000); err != nil {
+			return err
+		}
+
+		if _, ok := block["title"]; ok {
+			if err := validateText(block["title"], 2000); err != nil {
+				return fmt.Errorf("title: %s", err)
+			}
+		}
+
+	case "actions", "context":
+		elements, ok := block["elements"].([]interface{})
+
+		if !ok || len(elements) == 0 {
+			return fmt.Errorf("expected a list of elements")
+		}
+
+		if len(elements) > maxElements {
+			return fmt.Errorf("elements accepts up to %d items, got %d", maxElements, len(elements))
+		}
+
+	case "input":
+		if err := validateText(block["label"], 2000); err != nil {
+			return fmt.Errorf("label: %s", err)
+		}
+
+		if _, ok := block["element"].(map[string]interface{}); !ok {
+			return fmt.Errorf("missing element")
+		}
+
+	case "rich_text":
+		if _, ok := block["elements"].([]interface{}); !ok {
+			return fmt.Errorf("expected a list of elements")
+		}
+	}
+
+	return nil
+}
+
+// validateText checks a text object, with a type of plain_text or mrkdwn and
+// a non-empty text up to the given length.
+func validateText(value interface{}, limit int) error {
+	object, ok := value.(map[string]interface{})
+
+	if !ok {
+		return fmt.Errorf("expected a text object")
+	}
+
+	if kind := object["type"]; kind != "plain_text" && kind != "mrkdwn" {
+		return fmt.Errorf("type must be plain_text or mrkdwn")
+	}
+
+	text, ok := object["text"].(string)
+
+	if !ok || text == "" {
+		return fmt.Errorf("missing text")
+	}
+
+	if utf8.RuneCountInString(text) > limit {
+		return fmt.Errorf("text is longer than %d characters", limit)
+	}
+
+	return nil
+}
+
+// validateString checks the length of an optional string field.
+func validateString(block map[string]interface{}, key string, limit int) error {
+	if text, ok := block[key].(string); ok && utf8.RuneCountInString(text) > limit {
+		return fmt.Errorf("%s is longer than %d characters", key, limit)
+	}
+
+	return nil
+}
+
+// knownBlockTypes returns the types of block in alphabetical order.
+func knownBlockTypes() []string {
+	var types []string
+
+	for kind := range blockTypes {
+		types = append(types, kind)
+	}
+
+	sort.Strings(types)
+
+	return types
+}
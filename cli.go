@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
-	"log"
+	"io"
 	"os"
 	"strconv"
+	"strings"
+	"text/template"
 
 	"github.com/cixtor/slackapi"
 )
@@ -17,15 +19,32 @@ type Function func() int
 
 // CLI defines the core of the program.
 type CLI struct {
-	api      *slackapi.SlackAPI
-	commands []Command
+	api        *slackapi.SlackAPI
+	commands   []Command
+	index      map[string]int
+	namespaces map[string][]int
+	params     map[string]string
+	config     *Config
+	profile    Profile
+	store      CredentialStore
+	command    string
+	output     string
+	columns    []string
+	query      *Query
+	template   *template.Template
+	cache      *Cache
+	debug      bool
+	flags      *flag.FlagSet
+	stdin      io.Reader
+	stdout     io.Writer
+	stderr     io.Writer
 }
 
 // Command defines an option to call an API method.
 type Command struct {
 	Function Function
 	Name     string
-	Params   []string
+	Params   []Param
 	Help     string
 }
 
@@ -34,87 +53,285 @@ func NewCLI() *CLI {
 	cli := new(CLI)
 
 	cli.api = slackapi.New()
+	cli.flags = flag.CommandLine
+	cli.stdin = os.Stdin
+	cli.stdout = os.Stdout
+	cli.stderr = os.Stderr
 
 	return cli
 }
 
-// AutoAuthenticate searches for a token among the environment variablles to
-// authenticate the HTTP requests with the web API service.
-func (cli *CLI) AutoAuthenticate() {
-	cli.api.SetToken(os.Getenv("SLACK_TOKEN"))
-	cli.api.SetCookie(os.Getenv("SLACK_COOKIE"))
+// AutoAuthenticate searches for a token among the environment variablles, the
+// profiles in the configuration file and the credential store to authenticate
+// the HTTP requests with the web API service. If the name of the profile is
+// empty, it uses the default profile, if any. The environment variables take
+// precedence over the values in the profile, and the credential store is only
+// consulted when neither of them have a token.
+func (cli *CLI) AutoAuthenticate(name string) error {
+	if err := cli.LoadProfile(name); err != nil {
+		return err
+	}
+
+	cli.store = NewCredentialStore(cli.config.Get("", "credential_helper"))
+
+	if cli.profile.Token == "" && os.Getenv("SLACK_TOKEN") == "" {
+		creds, err := cli.store.Get(cli.profileKey())
+
+		if err != nil {
+			return err
+		}
+
+		cli.profile.Token = creds.Token
+		cli.profile.Cookie = creds.Cookie
+	}
+
+	if token := os.Getenv("SLACK_TOKEN"); token != "" {
+		cli.profile.Token = token
+	}
+
+	if cookie := os.Getenv("SLACK_COOKIE"); cookie != "" {
+		cli.profile.Cookie = cookie
+	}
+
+	cli.api.SetToken(cli.profile.Token)
+	cli.api.SetCookie(cli.profile.Cookie)
+
+	return nil
 }
 
-// Register adds support for a new command.
-func (cli *CLI) Register(fun Function, name string, params []string, help string) {
-	cli.commands = append(cli.commands, Command{fun, name, params, help})
+// LoadProfile reads the configuration file and selects a profile, without
+// looking for the credentials. If the name of the profile is empty, it uses
+// the default profile, if any.
+func (cli *CLI) LoadProfile(name string) error {
+	config, err := LoadConfig(ConfigPath())
+
+	if err != nil {
+		return err
+	}
+
+	cli.config = config
+
+	if name == "" {
+		name = config.CurrentProfile()
+	}
+
+	if name != "" {
+		if !config.HasProfile(name) {
+			return fmt.Errorf("profile %q does not exist", name)
+		}
+
+		cli.profile = config.Profile(name)
+	}
+
+	return nil
 }
 
-// Execute calls a method to send a HTTP request to the web API service.
-func (cli *CLI) Execute(query string) int {
-	for _, command := range cli.commands {
-		if command.Name == query {
-			return command.Function()
+// Register adds support for a new command. The command is indexed by its
+// name and by every namespace in its name, so chat.postMessage is in the
+// chat namespace and files.comments.add in files and files.comments.
+func (cli *CLI) Register(fun Function, name string, params []Param, help string) {
+	if cli.index == nil {
+		cli.index = map[string]int{}
+		cli.namespaces = map[string][]int{}
+	}
+
+	cli.index[name] = len(cli.commands)
+
+	for i := range name {
+		if name[i] == '.' {
+			cli.namespaces[name[:i]] = append(cli.namespaces[name[:i]], len(cli.commands))
 		}
 	}
 
-	return cli.CallHelp()
+	cli.commands = append(cli.commands, Command{fun, name, params, help})
 }
 
-// PrintCommands builds the usage options for the help command.
-func (cli *CLI) PrintCommands() {
-	for _, command := range cli.commands {
-		fmt.Printf("  slackcli %s", command.Name)
-		for _, param := range command.Params {
-			fmt.Printf(" [%s]", param)
+// Execute calls a method to send a HTTP request to the web API service. The
+// first argument is the command name, an alias or an unambiguous prefix of a
+// command name, the rest are the command parameters.
+func (cli *CLI) Execute(args []string) int {
+	if len(args) == 0 {
+		cli.flags.Usage()
+		return ExitUsage
+	}
+
+	args, err := cli.expandAlias(args)
+
+	if err != nil {
+		return cli.PrintError(err)
+	}
+
+	command, err := cli.LookupCommand(args[0])
+
+	if err != nil {
+		return cli.PrintError(err)
+	}
+
+	if wantsHelp(args[1:]) {
+		cli.printCommandHelp(command)
+		return ExitSuccess
+	}
+
+	params, err := ParseParams(command.Name, command.Params, expandPermalinks(command.Params, args[1:]))
+
+	if err != nil {
+		return cli.PrintError(UsageError("%s; %s", command.Name, err))
+	}
+
+	cli.params = params
+	cli.command = command.Name
+
+	if err := cli.expandPermalinkParams(command); err != nil {
+		return cli.PrintError(UsageError("%s; %s", command.Name, err))
+	}
+
+	cli.defaultChannel(command)
+
+	if err := cli.readInputs(command); err != nil {
+		return cli.PrintError(UsageError("%s; %s", command.Name, err))
+	}
+
+	if err := cli.resolveParams(command); err != nil {
+		e := ClassifyError(err)
+		e.Code = command.Name + "; " + e.Code
+		return cli.PrintError(e)
+	}
+
+	return command.Function()
+}
+
+// profileKey returns the name used to save the credentials of the active
+// profile in the credential store.
+func (cli *CLI) profileKey() string {
+	if cli.profile.Name == "" {
+		return "default"
+	}
+
+	return cli.profile.Name
+}
+
+// defaultChannel uses the channel from the active profile when the command
+// expects a channel or room but the user did not provide one.
+func (cli *CLI) defaultChannel(command Command) {
+	if cli.profile.Channel == "" {
+		return
+	}
+
+	for _, param := range command.Params {
+		if param.Name != "channel" && param.Name != "room" {
+			continue
+		}
+
+		if cli.params[param.Name] == "" {
+			cli.params[param.Name] = cli.profile.Channel
 		}
-		fmt.Println(" " + command.Help)
+	}
+}
+
+// PrintCommands builds the usage options for the help command.
+func (cli *CLI) PrintCommands(commands []Command) {
+	for _, command := range commands {
+		fmt.Fprintln(cli.stdout, "  "+commandUsage(command)+" "+command.Help)
 	}
 }
 
 // PrintJSON takes a generic struct with the response from the web API service
 // and then proceeds to encode it as a JSON string. It uses the JSON string to
 // obtain the status and possible errors from the HTTP request by re-decoding
-// into another smaller strust. It returns an Unix exit code representing the
-// success or failure of the operation, zero and one respectively.
+// into another smaller strust. Successful responses are printed with the
+// template or in the output format chosen by the user, errors are printed
+// by PrintError. It returns an Unix exit code representing the success or
+// the kind of failure of the operation.
 func (cli *CLI) PrintJSON(v interface{}) int {
 	var res slackapi.Response
 
 	out, err := json.MarshalIndent(v, "", "\x20\x20")
 
 	if err != nil {
-		fmt.Printf("{\"ok\":false, \"error\":\"json.encode; %s\"}\n", err.Error())
-		return 1
+		return cli.PrintError(fmt.Errorf("json.encode; %s", err))
 	}
 
 	if err := json.NewDecoder(bytes.NewReader(out)).Decode(&res); err != nil {
-		fmt.Printf("{\"ok\":false, \"error\":\"json.decode; %s\"}\n", err.Error())
-		return 1
+		return cli.PrintError(fmt.Errorf("json.decode; %s", err))
 	}
 
 	if !res.Ok && res.Error != "" {
-		if err := json.NewEncoder(os.Stdout).Encode(res); err != nil {
-			log.Fatalln("json.encode;", err)
+		return cli.PrintError(cli.responseError(out))
+	}
+
+	if cli.query != nil {
+		if out, err = cli.filter(out); err != nil {
+			return cli.PrintError(fmt.Errorf("query; %s", err))
 		}
+	}
+
+	if cli.template != nil {
+		return cli.printTemplate(out)
+	}
 
-		return 1
+	if cli.output != "" && cli.output != "json" {
+		return cli.printRendered(out)
 	}
 
-	fmt.Printf("%s\n", out)
+	fmt.Fprintf(cli.stdout, "%s\n", out)
 	return 0
 }
 
-// Number attempts to decode the user input as an integer.
-func (cli *CLI) Number(index int, initial int) int {
-	input := flag.Arg(index)
+// String returns the value of a command parameter.
+func (cli *CLI) String(name string) string {
+	return cli.params[name]
+}
+
+// ResponseOf re-decodes the response from the web API service into a smaller
+// struct to obtain the status and possible errors from the HTTP request.
+func ResponseOf(v interface{}) slackapi.Response {
+	var res slackapi.Response
+
+	out, err := json.Marshal(v)
+
+	if err != nil {
+		return slackapi.Response{Error: "json.encode; " + err.Error()}
+	}
 
-	if input == "" {
-		return initial
+	if err := json.Unmarshal(out, &res); err != nil {
+		return slackapi.Response{Error: "json.decode; " + err.Error()}
 	}
 
-	if number, err := strconv.Atoi(input); err == nil {
-		return number
+	return res
+}
+
+// Number attempts to decode a command parameter as an integer.
+func (cli *CLI) Number(name string) int {
+	number, _ := strconv.Atoi(cli.params[name])
+	return number
+}
+
+// Bool attempts to decode a command parameter as a boolean.
+func (cli *CLI) Bool(name string) bool {
+	value, _ := strconv.ParseBool(cli.params[name])
+	return value
+}
+
+// List splits a comma-separated command parameter.
+func (cli *CLI) List(name string) []string {
+	return strings.Split(cli.params[name], ",")
+}
+
+// Args returns the arguments collected by a RestParam.
+func (cli *CLI) Args(name string) []string {
+	if cli.params[name] == "" {
+		return nil
+	}
+
+	return strings.Split(cli.params[name], restSeparator)
+}
+
+// maskSecret hides most of a token or cookie, leaving enough characters to
+// identify which credential is in use.
+func maskSecret(secret string) string {
+	if len(secret) <= 12 {
+		return strings.Repeat("*", len(secret))
 	}
 
-	return initial
+	return secret[:5] + strings.Repeat("*", 8) + secret[len(secret)-4:]
 }
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the number of similar commands suggested for a typo.
+const maxSuggestions = 3
+
+// LookupCommand returns the command with the given name. A prefix of the
+// name is accepted if only one command starts with it, like chat.postM for
+// chat.postMessage. The name of a namespace, like chat or bots, is not a
+// prefix but a group of commands, even if the group has only one command.
+// Unknown names return an error that suggests the commands with a similar
+// name.
+func (cli *CLI) LookupCommand(name string) (Command, error) {
+	if i, ok := cli.index[name]; ok {
+		return cli.commands[i], nil
+	}
+
+	if _, ok := cli.namespaces[name]; ok {
+		return Command{}, UsageError("%q is a group of commands, use \"slackcli help %s\" to list them", name, name)
+	}
+
+	var matches []string
+
+	for _, command := range cli.commands {
+		if strings.HasPrefix(command.Name, name) {
+			matches = append(matches, command.Name)
+		}
+	}
+
+	if len(matches) == 1 {
+		return cli.commands[cli.index[matches[0]]], nil
+	}
+
+	if len(matches) > 1 {
+		return Command{}, UsageError("ambiguous command %q, it could be %s", name, orList(matches))
+	}
+
+	if suggestions := cli.suggestCommands(name); len(suggestions) > 0 {
+		return Command{}, UsageError("unknown command %q, did you mean %s?", name, orList(suggestions))
+	}
+
+	return Command{}, UsageError("unknown command %q, use \"slackcli help\" to list the commands", name)
+}
+
+// Namespace returns the commands whose name starts with the namespace and a
+// dot, in the order they were registered.
+func (cli *CLI) Namespace(name string) []Command {
+	var commands []Command
+
+	for _, i := range cli.namespaces[name] {
+		commands = append(commands, cli.commands[i])
+	}
+
+	return commands
+}
+
+// Aliases returns the aliases defined in the alias section of the
+// configuration file, for example:
+//
+//	[alias]
+//	post = chat.postMessage
+//	ops = chat.postMessage #ops
+func (cli *CLI) Aliases() map[string]string {
+	aliases := map[string]string{}
+
+	if cli.config == nil || !cli.config.HasSection("alias") {
+		return aliases
+	}
+
+	section := cli.config.Section("alias")
+
+	for _, key := range section.Keys() {
+		aliases[key] = section.Get(key)
+	}
+
+	return aliases
+}
+
+// expandAlias replaces an alias in the first argument with the command and
+// the arguments that it stands for. Commands take precedence over aliases
+// with the same name, and aliases are not expanded recursively.
+func (cli *CLI) expandAlias(args []string) ([]string, error) {
+	if _, ok := cli.index[args[0]]; ok {
+		return args, nil
+	}
+
+	value, ok := cli.Aliases()[args[0]]
+
+	if !ok {
+		return args, nil
+	}
+
+	words, err := splitWords(value)
+
+	if err != nil || len(words) == 0 {
+		return nil, UsageError("alias %q is not valid: %q", args[0], value)
+	}
+
+	return append(words, args[1:]...), nil
+}
+
+// suggestCommands returns the commands with a name similar to the input, the
+// closest first. The allowed distance grows with the length of the input, so
+// long names tolerate more typos.
+func (cli *CLI) suggestCommands(name string) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+
+	var suggestions []suggestion
+
+	limit := len(name) / 4
+
+	if limit < 2 {
+		limit = 2
+	}
+
+	for _, command := range cli.commands {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(command.Name))
+
+		if distance <= limit {
+			suggestions = append(suggestions, suggestion{command.Name, distance})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+
+	var names []string
+
+	for i := 0; i < len(suggestions) && i < maxSuggestions; i++ {
+		names = append(names, suggestions[i].name)
+	}
+
+	return names
+}
+
+// editDistance returns the Levenshtein distance between two words, which is
+// the number of insertions, deletions or substitutions to turn one into the
+// other.
+func editDistance(a string, b string) int {
+	x, y := []rune(a), []rune(b)
+
+	prev := make([]int, len(y)+1)
+	curr := make([]int, len(y)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(x); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, minInt(curr[j-1]+1, prev[j-1]+cost))
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(y)]
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+// orList joins the names like "a, b or c".
+func orList(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
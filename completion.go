@@ -0,0 +1,469 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// completionData describes the commands and flags of the program for the
+// templates of the completion scripts.
+type completionData struct {
+	Commands []completionCommand
+	Flags    []completionFlag
+}
+
+// completionCommand defines a command in a completion script. Arguments has
+// the values suggested for the positional parameters, without duplicates.
+type completionCommand struct {
+	Name      string
+	Help      string
+	Params    []completionParam
+	Arguments []string
+}
+
+// completionParam defines a command parameter in a completion script. Names
+// is "channels" or "users" when the values come from the cache.
+type completionParam struct {
+	Name   string
+	Bool   bool
+	Values []string
+	Names  string
+}
+
+// completionFlag defines a flag of the program in a completion script.
+type completionFlag struct {
+	Name  string
+	Bool  bool
+	Usage string
+}
+
+// CallCompletion prints a script that completes the commands, the parameters
+// and their values in bash, zsh, fish or PowerShell. The scripts complete the
+// channel and user names with the cache, which they read with --names.
+func (cli *CLI) CallCompletion() int {
+	if names := cli.String("names"); names != "" {
+		return cli.printNames(names)
+	}
+
+	var text string
+
+	switch cli.String("shell") {
+	case "bash":
+		text = bashHeader + bashCompletion
+	case "zsh":
+		text = zshCompletion + bashCompletion
+	case "fish":
+		text = fishCompletion
+	case "powershell":
+		text = powershellCompletion
+	default:
+		return cli.PrintError(UsageError("completion; choose one shell: bash, zsh, fish or powershell"))
+	}
+
+	tmpl, err := template.New("completion").Funcs(template.FuncMap{
+		"join":   strings.Join,
+		"fish":   fishQuote,
+		"pslist": powershellList,
+	}).Parse(text)
+
+	if err != nil {
+		return cli.PrintError(fmt.Errorf("completion; %s", err))
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, cli.completionData()); err != nil {
+		return cli.PrintError(fmt.Errorf("completion; %s", err))
+	}
+
+	cli.stdout.Write(buf.Bytes())
+
+	return ExitSuccess
+}
+
+// printNames prints the channels or the users in the cache, one per line. It
+// does not download the cache if it is missing or expired, so completing a
+// word does not wait for the network.
+func (cli *CLI) printNames(kind string) int {
+	cache, err := cli.readCache()
+
+	if err != nil {
+		return cli.PrintError(NewError(ErrorNotFound, "completion; cache does not exist, use \"slackcli cache.refresh\""))
+	}
+
+	if kind == "channels" {
+		for _, channel := range cache.Channels {
+			fmt.Fprintln(cli.stdout, "#"+channel.Name)
+		}
+	}
+
+	if kind == "users" {
+		for _, user := range cache.Users {
+			fmt.Fprintln(cli.stdout, "@"+user.Name)
+		}
+	}
+
+	return ExitSuccess
+}
+
+// completionData collects the registered commands and the program flags.
+func (cli *CLI) completionData() completionData {
+	var data completionData
+
+	for _, command := range cli.commands {
+		entry := completionCommand{Name: command.Name, Help: command.Help}
+		seen := map[string]bool{}
+
+		for _, param := range command.Params {
+			item := completionParam{Name: param.Name, Bool: param.Type == TypeBool, Values: param.Values}
+
+			switch param.Resolve {
+			case ResolveChannel:
+				item.Names = "channels"
+			case ResolveUser:
+				item.Names = "users"
+			}
+
+			if item.Bool {
+				item.Values = []string{"true", "false"}
+			}
+
+			entry.Params = append(entry.Params, item)
+
+			if item.Bool {
+				continue
+			}
+
+			argument := strings.Join(item.Values, "\x20")
+
+			if item.Names != "" {
+				argument = "(slackcli completion --names=" + item.Names + " 2>/dev/null)"
+			}
+
+			if argument != "" && !seen[argument] {
+				seen[argument] = true
+				entry.Arguments = append(entry.Arguments, argument)
+			}
+		}
+
+		data.Commands = append(data.Commands, entry)
+	}
+
+	cli.flags.VisitAll(func(f *flag.Flag) {
+		value, ok := f.Value.(interface{ IsBoolFlag() bool })
+
+		data.Flags = append(data.Flags, completionFlag{
+			Name:  f.Name,
+			Bool:  ok && value.IsBoolFlag(),
+			Usage: f.Usage,
+		})
+	})
+
+	return data
+}
+
+// fishQuote returns the text in single quotes for the fish shell.
+func fishQuote(text string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(text) + "'"
+}
+
+// powershellList returns the values as a PowerShell array literal.
+func powershellList(values []string) string {
+	quoted := make([]string, len(values))
+
+	for i, value := range values {
+		quoted[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+
+	return "@(" + strings.Join(quoted, ", ") + ")"
+}
+
+// bashHeader explains how to load the bash completion.
+const bashHeader = `# bash completion for slackcli, generated by "slackcli completion bash"
+# source <(slackcli completion bash)
+
+`
+
+// bashCompletion completes the commands in bash. Bash splits the words at the
+// equal sign and the at sign, so --types=all arrives as three words and
+// @alice as two; the loop skips them to count the positional arguments.
+const bashCompletion = `_slackcli_commands="
+{{- range .Commands}}
+{{.Name}}
+{{- end}}
+"
+
+_slackcli_flags="
+{{- range .Flags}}
+-{{.Name}}
+{{- end}}
+"
+
+_slackcli_params() {
+	case "$1" in
+{{- range .Commands}}
+	{{.Name}}) echo "{{range $i, $p := .Params}}{{if $i}} {{end}}{{.Name}}{{if not .Bool}}={{end}}{{end}}" ;;
+{{- end}}
+	esac
+}
+
+_slackcli_values() {
+	case "$1 $2" in
+{{- range $command := .Commands}}{{range .Params}}{{if .Names}}
+	"{{$command.Name}} {{.Name}}") slackcli completion --names={{.Names}} 2>/dev/null ;;
+{{- else if .Values}}
+	"{{$command.Name}} {{.Name}}") echo "{{join .Values " "}}" ;;
+{{- end}}{{end}}{{end}}
+	esac
+}
+
+_slackcli() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	local prev="${COMP_WORDS[COMP_CWORD-1]}"
+	local command="" flag="" position=0 i word name
+	local params
+
+	COMPREPLY=()
+
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		word="${COMP_WORDS[i]}"
+
+		case "$word" in
+		"=") i=$((i + 1)); continue ;;
+		"@") continue ;;
+		esac
+
+		if [[ -z "$command" ]]; then
+			case "$word" in
+{{- range .Flags}}{{if not .Bool}}
+			-{{.Name}}|--{{.Name}}) [[ "${COMP_WORDS[i+1]}" == "=" ]] || i=$((i + 1)) ;;
+{{- end}}{{end}}
+			-*) ;;
+			*) command="$word" ;;
+			esac
+		elif [[ "$word" == -* ]]; then
+			name="${word#-}"
+			name="${name#-}"
+
+			if [[ " $(_slackcli_params "$command") " == *" $name= "* && "${COMP_WORDS[i+1]}" != "=" ]]; then
+				i=$((i + 1))
+			fi
+		else
+			position=$((position + 1))
+		fi
+	done
+
+	if [[ "$cur" == "=" ]]; then
+		flag="$prev"
+		cur=""
+	elif [[ "$prev" == "=" ]]; then
+		flag="${COMP_WORDS[COMP_CWORD-2]}"
+	elif [[ "$prev" == -* && "$prev" != "$command" ]]; then
+		name="${prev#-}"
+		name="${name#-}"
+
+		if [[ -n "$command" && " $(_slackcli_params "$command") " == *" $name= "* ]]; then
+			flag="$prev"
+		fi
+	fi
+
+	if [[ -z "$command" ]]; then
+		if [[ -n "$flag" ]]; then
+			return 0
+		fi
+
+		case "$prev" in
+{{- range .Flags}}{{if not .Bool}}
+		-{{.Name}}|--{{.Name}}) return 0 ;;
+{{- end}}{{end}}
+		esac
+
+		if [[ "$cur" == -* ]]; then
+			COMPREPLY=($(compgen -W "$_slackcli_flags" -- "$cur"))
+		else
+			COMPREPLY=($(compgen -W "$_slackcli_commands" -- "$cur"))
+		fi
+
+		return 0
+	fi
+
+	if [[ "$cur" == "@" || "$prev" == "@" ]]; then
+		[[ "$cur" == "@" ]] && cur=""
+		COMPREPLY=($(compgen -W "$(slackcli completion --names=users 2>/dev/null | sed 's/^@//')" -- "$cur"))
+		return 0
+	fi
+
+	if [[ "$cur" == "#"* ]]; then
+		COMPREPLY=($(compgen -W "$(slackcli completion --names=channels 2>/dev/null)" -- "$cur"))
+		return 0
+	fi
+
+	if [[ -n "$flag" ]]; then
+		name="${flag#-}"
+		name="${name#-}"
+		COMPREPLY=($(compgen -W "$(_slackcli_values "$command" "$name")" -- "$cur"))
+		return 0
+	fi
+
+	if [[ "$cur" == -* ]]; then
+		COMPREPLY=($(compgen -P "--" -W "$(_slackcli_params "$command")" -- "${cur##*-}"))
+
+		if [[ ${#COMPREPLY[@]} -eq 1 && "${COMPREPLY[0]}" == *= ]]; then
+			compopt -o nospace
+		fi
+
+		return 0
+	fi
+
+	params=($(_slackcli_params "$command"))
+	name="${params[position]%=}"
+
+	if [[ -n "$name" ]]; then
+		COMPREPLY=($(compgen -W "$(_slackcli_values "$command" "$name")" -- "$cur"))
+	fi
+
+	return 0
+}
+
+complete -F _slackcli slackcli
+`
+
+// zshCompletion loads the bash completion in zsh.
+const zshCompletion = `# zsh completion for slackcli, generated by "slackcli completion zsh"
+# source <(slackcli completion zsh)
+
+autoload -U +X bashcompinit && bashcompinit
+
+`
+
+// fishCompletion completes the commands in fish.
+const fishCompletion = `# fish completion for slackcli, generated by "slackcli completion fish"
+# slackcli completion fish | source
+
+complete -c slackcli -f
+{{range .Flags}}
+complete -c slackcli -n __fish_use_subcommand -o {{.Name}}{{if not .Bool}} -r{{end}} -d {{fish .Usage}}
+{{- end}}
+{{range .Commands}}
+complete -c slackcli -n __fish_use_subcommand -a {{.Name}} -d {{fish .Help}}
+{{- end}}
+{{range $command := .Commands}}{{range .Params}}
+complete -c slackcli -n '__fish_seen_subcommand_from {{$command.Name}}' -l {{.Name}}
+{{- if .Names}} -x -a '(slackcli completion --names={{.Names}} 2>/dev/null)'
+{{- else if .Bool}}
+{{- else if .Values}} -x -a {{fish (join .Values " ")}}
+{{- else}} -r{{end}}
+{{- end}}{{range .Arguments}}
+complete -c slackcli -n '__fish_seen_subcommand_from {{$command.Name}}' -a {{fish .}}
+{{- end}}{{end}}
+`
+
+// powershellCompletion completes the commands in PowerShell. Values starting
+// with a colon are read from the cache, and the names are quoted because the
+// hash starts a comment and the at sign a splat.
+const powershellCompletion = `# powershell completion for slackcli, generated by "slackcli completion powershell"
+# slackcli completion powershell | Out-String | Invoke-Expression
+
+Register-ArgumentCompleter -Native -CommandName slackcli -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $flags = @({{range $i, $f := .Flags}}{{if $i}}, {{end}}'-{{.Name}}'{{end}})
+    $valueFlags = @(
+{{- range .Flags}}{{if not .Bool}}
+        '-{{.Name}}'
+        '--{{.Name}}'
+{{- end}}{{end}}
+    )
+
+    $params = @{
+{{- range .Commands}}
+        '{{.Name}}' = @({{range $i, $p := .Params}}{{if $i}}, {{end}}'{{.Name}}{{if not .Bool}}={{end}}'{{end}})
+{{- end}}
+    }
+
+    $values = @{
+{{- range $command := .Commands}}{{range .Params}}{{if .Names}}
+        '{{$command.Name}} {{.Name}}' = ':{{.Names}}'
+{{- else if .Values}}
+        '{{$command.Name}} {{.Name}}' = {{pslist .Values}}
+{{- end}}{{end}}{{end}}
+    }
+
+    $expand = {
+        param($value)
+
+        if ($value -is [string] -and $value.StartsWith(':')) {
+            & slackcli completion "--names=$($value.Substring(1))" 2>$null
+        } else {
+            $value
+        }
+    }
+
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.Extent.Text })
+
+    if ($wordToComplete) {
+        $words = @($words | Select-Object -First ($words.Count - 1))
+    }
+
+    $command = $null
+    $position = 0
+
+    for ($i = 0; $i -lt $words.Count; $i++) {
+        $word = $words[$i]
+
+        if (-not $command) {
+            if ($valueFlags -contains $word) {
+                $i++
+            } elseif ($word -notlike '-*') {
+                $command = $word
+            }
+
+            continue
+        }
+
+        if ($word -like '-*') {
+            if ($params[$command] -contains "$($word.TrimStart('-'))=") {
+                $i++
+            }
+        } else {
+            $position++
+        }
+    }
+
+    $prefix = ''
+    $candidates = @()
+
+    if (-not $command) {
+        if ($wordToComplete -like '-*') {
+            $candidates = $flags
+        } else {
+            $candidates = $params.Keys
+        }
+    } elseif ($wordToComplete -like '-*=*') {
+        $name = $wordToComplete.Split('=')[0]
+        $prefix = "$name="
+        $candidates = & $expand $values["$command $($name.TrimStart('-'))"]
+    } elseif ($wordToComplete -like '-*') {
+        $candidates = $params[$command] | ForEach-Object { "--$_" }
+    } else {
+        $positional = @($params[$command] | ForEach-Object { $_.TrimEnd('=') })
+
+        if ($position -lt $positional.Count) {
+            $candidates = & $expand $values["$command $($positional[$position])"]
+        }
+    }
+
+    $candidates | ForEach-Object { "$prefix$_" } | Where-Object { $_.StartsWith($wordToComplete.Trim("'")) } | Sort-Object | ForEach-Object {
+        $text = $_
+
+        if ($text -match '^[#@]' -or $text -match '=[#@]') {
+            $text = "'$text'"
+        }
+
+        [System.Management.Automation.CompletionResult]::new($text, $_, 'ParameterValue', $_)
+    }
+}
+`
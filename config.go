@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Config holds the content of the configuration file. The file uses an INI
+// format where each section groups related settings, for example:
+//
+//	profile = work
+//
+//	[profile work]
+//	token = xoxc-...
+//	cookie = xoxd-...
+//	channel = general
+type Config struct {
+	path     string
+	sections []*ConfigSection
+}
+
+// ConfigSection defines a group of settings in the configuration file. The
+// section with an empty name holds the settings that come before any header.
+type ConfigSection struct {
+	Name   string
+	keys   []string
+	values map[string]string
+}
+
+// Profile defines the credentials and preferences for one workspace.
+type Profile struct {
+	Name       string `json:"name"`
+	Token      string `json:"token,omitempty"`
+	Cookie     string `json:"cookie,omitempty"`
+	Channel    string `json:"channel,omitempty"`
+	RobotName  string `json:"robot_name,omitempty"`
+	RobotImage string `json:"robot_image,omitempty"`
+	APIURL     string `json:"api_url,omitempty"`
+}
+
+// ConfigPath returns the location of the configuration file, which follows
+// the XDG base directory specification.
+func ConfigPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "slackcli", "config")
+	}
+
+	home, err := os.UserHomeDir()
+
+	if err != nil {
+		return filepath.Join(".config", "slackcli", "config")
+	}
+
+	return filepath.Join(home, ".config", "slackcli", "config")
+}
+
+// LoadConfig reads and parses the configuration file. A missing file is not
+// an error, it returns an empty configuration that can be saved later.
+func LoadConfig(path string) (*Config, error) {
+	config := &Config{path: path}
+
+	data, err := os.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	section := config.Section("")
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			section = config.Section(strings.Join(strings.Fields(line[1:len(line)-1]), "\x20"))
+			continue
+		}
+
+		index := strings.Index(line, "=")
+
+		if index == -1 {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, number)
+		}
+
+		section.Set(strings.TrimSpace(line[:index]), strings.TrimSpace(line[index+1:]))
+	}
+
+	return config, scanner.Err()
+}
+
+// Save writes the configuration file, only the current user can read it
+// because it contains credentials.
+func (c *Config) Save() error {
+	var buf bytes.Buffer
+
+	for _, section := range c.sections {
+		if section.Name == "" && len(section.keys) == 0 {
+			continue
+		}
+
+		if section.Name != "" {
+			if buf.Len() > 0 {
+				buf.WriteString("\n")
+			}
+
+			fmt.Fprintf(&buf, "[%s]\n", section.Name)
+		}
+
+		for _, key := range section.keys {
+			fmt.Fprintf(&buf, "%s = %s\n", key, section.values[key])
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, buf.Bytes(), 0600)
+}
+
+// Section returns the section with the given name, creating it if necessary.
+func (c *Config) Section(name string) *ConfigSection {
+	if section := c.lookup(name); section != nil {
+		return section
+	}
+
+	section := &ConfigSection{Name: name, values: map[string]string{}}
+	c.sections = append(c.sections, section)
+
+	return section
+}
+
+// HasSection checks if the configuration file defines a section.
+func (c *Config) HasSection(name string) bool {
+	return c.lookup(name) != nil
+}
+
+// Get returns the value of a setting without creating the section.
+func (c *Config) Get(name string, key string) string {
+	if section := c.lookup(name); section != nil {
+		return section.Get(key)
+	}
+
+	return ""
+}
+
+func (c *Config) lookup(name string) *ConfigSection {
+	for _, section := range c.sections {
+		if section.Name == name {
+			return section
+		}
+	}
+
+	return nil
+}
+
+// RemoveSection deletes a section and all its settings.
+func (c *Config) RemoveSection(name string) {
+	for i, section := range c.sections {
+		if section.Name == name {
+			c.sections = append(c.sections[:i], c.sections[i+1:]...)
+			return
+		}
+	}
+}
+
+// Get returns the value of a setting or an empty string.
+func (s *ConfigSection) Get(key string) string {
+	return s.values[key]
+}
+
+// Set changes the value of a setting, empty values remove the setting.
+func (s *ConfigSection) Set(key string, value string) {
+	if value == "" {
+		s.Unset(key)
+		return
+	}
+
+	if _, ok := s.values[key]; !ok {
+		s.keys = append(s.keys, key)
+	}
+
+	s.values[key] = value
+}
+
+// Unset removes a setting from the section.
+func (s *ConfigSection) Unset(key string) {
+	if _, ok := s.values[key]; !ok {
+		return
+	}
+
+	delete(s.values, key)
+
+	for i, k := range s.keys {
+		if k == key {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the name of all the settings in the section.
+func (s *ConfigSection) Keys() []string {
+	return s.keys
+}
+
+// CurrentProfile returns the name of the profile used by default.
+func (c *Config) CurrentProfile() string {
+	return c.Get("", "profile")
+}
+
+// SetCurrentProfile changes the profile used by default.
+func (c *Config) SetCurrentProfile(name string) {
+	c.Section("").Set("profile", name)
+}
+
+// ProfileNames returns the name of all the profiles in the file.
+func (c *Config) ProfileNames() []string {
+	var names []string
+
+	for _, section := range c.sections {
+		if strings.HasPrefix(section.Name, "profile ") {
+			names = append(names, strings.TrimPrefix(section.Name, "profile "))
+		}
+	}
+
+	return names
+}
+
+// HasProfile checks if a profile exists.
+func (c *Config) HasProfile(name string) bool {
+	return c.HasSection("profile " + name)
+}
+
+// Profile returns the settings of a profile.
+func (c *Config) Profile(name string) Profile {
+	section := "profile " + name
+
+	return Profile{
+		Name:       name,
+		Token:      c.Get(section, "token"),
+		Cookie:     c.Get(section, "cookie"),
+		Channel:    c.Get(section, "channel"),
+		RobotName:  c.Get(section, "robot_name"),
+		RobotImage: c.Get(section, "robot_image"),
+		APIURL:     c.Get(section, "api_url"),
+	}
+}
+
+// SetProfile creates or replaces the settings of a profile.
+func (c *Config) SetProfile(profile Profile) {
+	section := c.Section("profile " + profile.Name)
+
+	section.Set("token", profile.Token)
+	section.Set("cookie", profile.Cookie)
+	section.Set("channel", profile.Channel)
+	section.Set("robot_name", profile.RobotName)
+	section.Set("robot_image", profile.RobotImage)
+	section.Set("api_url", profile.APIURL)
+}
+
+// RemoveProfile deletes a profile, if the profile was the default one, the
+// configuration is left without a default profile.
+func (c *Config) RemoveProfile(name string) {
+	c.RemoveSection("profile " + name)
+
+	if c.CurrentProfile() == name {
+		c.SetCurrentProfile("")
+	}
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Credentials holds the secrets necessary to authenticate a session.
+type Credentials struct {
+	Token  string `json:"token"`
+	Cookie string `json:"cookie,omitempty"`
+}
+
+// CredentialStore saves and retrieves the credentials of each profile.
+type CredentialStore interface {
+	Get(profile string) (Credentials, error)
+	Store(profile string, creds Credentials) error
+	Erase(profile string) error
+}
+
+// NewCredentialStore returns the credential store selected by the user. The
+// name follows the same convention as the credential helpers in Git: "file"
+// is the built-in encrypted file, a name starting with an exclamation mark is
+// executed as a shell command, an absolute path is executed as is, and any
+// other name is expanded to an executable called slackcli-credential-NAME.
+func NewCredentialStore(name string) CredentialStore {
+	if name == "" || name == "file" {
+		return &FileStore{Path: filepath.Join(filepath.Dir(ConfigPath()), "credentials")}
+	}
+
+	if name[0] == '!' {
+		return &HelperStore{Command: []string{"/bin/sh", "-c", name[1:] + ` "$@"`, name[1:]}}
+	}
+
+	if filepath.IsAbs(name) {
+		return &HelperStore{Command: []string{name}}
+	}
+
+	return &HelperStore{Command: []string{"slackcli-credential-" + name}}
+}
+
+// FileStore keeps the credentials in a file encrypted with AES-GCM using a
+// key derived from a passphrase. The passphrase is read from SLACK_PASSPHRASE
+// or, if the variable is not set, requested in the terminal.
+type FileStore struct {
+	Path       string
+	passphrase string
+}
+
+const (
+	fileStoreMagic      = "slackcli-credentials-v1\n"
+	fileStoreSaltSize   = 16
+	fileStoreIterations = 210000
+)
+
+// Get returns the credentials of a profile. A missing file is not an error,
+// in which case the credentials are empty.
+func (s *FileStore) Get(profile string) (Credentials, error) {
+	entries, err := s.load()
+
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	return entries[profile], nil
+}
+
+// Store saves the credentials of a profile.
+func (s *FileStore) Store(profile string, creds Credentials) error {
+	entries, err := s.load()
+
+	if err != nil {
+		return err
+	}
+
+	entries[profile] = creds
+
+	return s.save(entries)
+}
+
+// Erase deletes the credentials of a profile.
+func (s *FileStore) Erase(profile string) error {
+	entries, err := s.load()
+
+	if err != nil {
+		return err
+	}
+
+	if _, ok := entries[profile]; !ok {
+		return nil
+	}
+
+	delete(entries, profile)
+
+	return s.save(entries)
+}
+
+func (s *FileStore) load() (map[string]Credentials, error) {
+	entries := map[string]Credentials{}
+
+	data, err := os.ReadFile(s.Path)
+
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(data, []byte(fileStoreMagic)) {
+		return nil, errors.New("credentials file has an unknown format")
+	}
+
+	data = data[len(fileStoreMagic):]
+
+	if len(data) < fileStoreSaltSize {
+		return nil, errors.New("credentials file is truncated")
+	}
+
+	aead, err := s.cipher(data[:fileStoreSaltSize])
+
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[fileStoreSaltSize:]
+
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("credentials file is truncated")
+	}
+
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+
+	if err != nil {
+		return nil, errors.New("cannot decrypt credentials, wrong passphrase?")
+	}
+
+	if err := json.Unmarshal(plain, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (s *FileStore) save(entries map[string]Credentials) error {
+	plain, err := json.Marshal(entries)
+
+	if err != nil {
+		return err
+	}
+
+	salt := make([]byte, fileStoreSaltSize)
+
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	aead, err := s.cipher(salt)
+
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString(fileStoreMagic)
+	buf.Write(salt)
+	buf.Write(nonce)
+	buf.Write(aead.Seal(nil, nonce, plain, nil))
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.Path, buf.Bytes(), 0600)
+}
+
+func (s *FileStore) cipher(salt []byte) (cipher.AEAD, error) {
+	if s.passphrase == "" {
+		s.passphrase = os.Getenv("SLACK_PASSPHRASE")
+	}
+
+	if s.passphrase == "" {
+		passphrase, err := readSecret("passphrase", "Passphrase for "+s.Path+": ", "set SLACK_PASSPHRASE")
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.passphrase = passphrase
+	}
+
+	if s.passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(s.passphrase), salt, fileStoreIterations, 32, sha256.New))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// HelperStore delegates the storage of the credentials to an external program
+// that follows a protocol similar to the Git credential helpers. The program
+// receives the action (get, store or erase) as its first argument and a list
+// of key=value lines in the standard input, terminated by an empty line. The
+// "get" action must print the token and cookie in the same format.
+//
+//	$ printf 'profile=work\n\n' | slackcli-credential-pass get
+//	token=xoxc-...
+//	cookie=xoxd-...
+type HelperStore struct {
+	Command []string
+}
+
+// Get returns the credentials of a profile.
+func (s *HelperStore) Get(profile string) (Credentials, error) {
+	out, err := s.run("get", map[string]string{"profile": profile})
+
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	return Credentials{Token: out["token"], Cookie: out["cookie"]}, nil
+}
+
+// Store saves the credentials of a profile.
+func (s *HelperStore) Store(profile string, creds Credentials) error {
+	_, err := s.run("store", map[string]string{
+		"profile": profile,
+		"token":   creds.Token,
+		"cookie":  creds.Cookie,
+	})
+
+	return err
+}
+
+// Erase deletes the credentials of a profile.
+func (s *HelperStore) Erase(profile string) error {
+	_, err := s.run("erase", map[string]string{"profile": profile})
+	return err
+}
+
+func (s *HelperStore) run(action string, input map[string]string) (map[string]string, error) {
+	var stdin bytes.Buffer
+	var stdout bytes.Buffer
+
+	for _, key := range []string{"profile", "token", "cookie"} {
+		if value, ok := input[key]; ok && value != "" {
+			fmt.Fprintf(&stdin, "%s=%s\n", key, value)
+		}
+	}
+
+	stdin.WriteString("\n")
+
+	args := append(append([]string{}, s.Command[1:]...), action)
+	cmd := exec.Command(s.Command[0], args...)
+	cmd.Stdin = &stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("credential helper %s: %s", s.Command[0], err)
+	}
+
+	out := map[string]string{}
+	scanner := bufio.NewScanner(&stdout)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			break
+		}
+
+		if index := strings.Index(line, "="); index > 0 {
+			out[line[:index]] = line[index+1:]
+		}
+	}
+
+	return out, scanner.Err()
+}
+
+// readSecret prints a prompt in the standard error and reads one line from
+// the terminal without echoing the characters, if the terminal allows it. If
+// there is no terminal, the error names the secret and suggests another way
+// to pass it.
+func readSecret(name string, prompt string, hint string) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+
+	if err != nil {
+		return "", fmt.Errorf("no terminal to read the %s, %s", name, hint)
+	}
+
+	defer tty.Close()
+
+	fmt.Fprint(os.Stderr, prompt)
+
+	if stty(tty, "-echo") == nil {
+		defer stty(tty, "echo")
+	}
+
+	line, err := bufio.NewReader(tty).ReadString('\n')
+
+	fmt.Fprintln(os.Stderr)
+
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func stty(tty *os.File, args ...string) error {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	return cmd.Run()
+}
+
+// isTerminal returns true if the file is a terminal rather than a pipe or a
+// regular file.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
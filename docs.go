@@ -0,0 +1,327 @@
+package main
+
+// paramHelp describes the command parameters. The key is the name of the
+// parameter, or the name of the command and the parameter separated by a
+// space when the parameter means something else in that command.
+var paramHelp = map[string]string{
+	"abn_id":                   "Australian Business Number",
+	"all":                      "Follow the pagination until the results are exhausted",
+	"api_url":                  "Address of the web API service used by the profile",
+	"app_id":                   "ID of the app",
+	"args":                     "Arguments of the method: key=value, key:=json, key=@file or @file",
+	"attachment":               "Position of the attachment in the message, starting at 1",
+	"before":                   "Unix timestamp of the most recent entry to include",
+	"blocks":                   "Block Kit layout, as a JSON list of blocks or an object with a blocks field",
+	"bot":                      "ID of the bot user",
+	"build_version_ts":         "Build version timestamp of the client",
+	"change_type":              "Kind of change: added, removed, enabled, disabled or updated",
+	"channel":                  "Channel ID, #name, or @user for a direct message",
+	"channel_id":               "ID of the channel of the shared invite",
+	"channel_name":             "Name of the channel to create for the shared invite",
+	"channels":                 "Comma-separated channel IDs or #names",
+	"city":                     "City of the billing address",
+	"company_name":             "Company name of the billing address",
+	"command":                  "Name of the command",
+	"concurrency":              "Number of commands executed at the same time",
+	"config_version_ts":        "Configuration version timestamp of the client",
+	"cookie":                   "Session cookie that goes with a xoxc token",
+	"count":                    "Number of items per page",
+	"country":                  "Country of the billing address",
+	"cursor":                   "Cursor of the page, from response_metadata.next_cursor",
+	"dir":                      "Folder where the man pages are written",
+	"default_channel":          "Channel used when a command expects one and none is given",
+	"email":                    "Email address",
+	"emails":                   "Comma-separated email addresses of the external users",
+	"emoji":                    "Emoji of the status, like :coffee:",
+	"error":                    "Error code that the method returns",
+	"event_context":            "Event context from the event payload",
+	"external_limited":         "Give the external users limited access to the channel",
+	"fcid":                     "ID of the file comment",
+	"file":                     "ID of the file",
+	"filename":                 "Path of the file to upload",
+	"free_trial_accepted":      "Accept the free trial of the paid plan",
+	"get":                      "Send the arguments in the query string with a GET request",
+	"image":                    "Path of the image",
+	"image_id":                 "ID returned by users.preparePhoto",
+	"include_icon":             "Include the icon of each workspace",
+	"inclusive":                "Include the messages with the oldest and latest timestamps",
+	"invite_id":                "ID of the shared channel invite",
+	"is_business":              "The billing address belongs to a business",
+	"is_checkout_v2":           "Use the second version of the checkout",
+	"is_private":               "Create a private channel",
+	"is_vat_registered":        "The business is registered for VAT",
+	"item_id":                  "Timestamp of the message or ID of the file",
+	"json":                     "Attachment as a JSON object",
+	"latest":                   "Timestamp of the most recent message to include",
+	"limit":                    "Maximum number of items per page",
+	"link-names":               "Link the @names and #channels in the text",
+	"manifest":                 "App manifest as a JSON object",
+	"max-items":                "Stop after this number of items, implies --all",
+	"max-pages":                "Stop after this number of pages, implies --all",
+	"method":                   "Name of the method, like bookmarks.list",
+	"minutes":                  "Number of minutes to snooze the notifications",
+	"module":                   "Kind of results: messages, files, channels or people",
+	"mrkdwn":                   "Format the text with the Slack markup",
+	"name":                     "Name",
+	"names":                    "Print the channels or the users in the cache",
+	"no-unfurl":                "Show no preview of the links nor the media",
+	"notes":                    "Notes of the billing address",
+	"oldest":                   "Timestamp of the oldest message to include",
+	"order":                    "Return the users in the same order as the input",
+	"page":                     "Page number",
+	"parse":                    "Treatment of the text: none, or full to link the names and URLs",
+	"permalink":                "URL of the message, from \"Copy link\" in the Slack clients",
+	"post_at":                  "Unix time, date and time, +2h or a phrase like \"tomorrow 9:00\" in your time zone",
+	"presence":                 "Presence of the user",
+	"prevent_creation":         "Do not create the conversation if it does not exist",
+	"purpose":                  "New purpose of the channel",
+	"query":                    "Search query, with modifiers like in:#channel or from:@user",
+	"reply-broadcast":          "Also show the reply in the channel, with thread-ts",
+	"return_im":                "Return the full direct message channel",
+	"robot_image":              "Emoji or image URL used by chat.robotMessage",
+	"robot_name":               "Bot name used by chat.robotMessage",
+	"room":                     "Channel ID, #name, or @user for a direct message",
+	"scheduled_message_id":     "ID of the scheduled message, from chat.scheduledMessages.list",
+	"service_id":               "ID of the service",
+	"shell":                    "Shell: bash, zsh, fish or powershell",
+	"state":                    "State of the billing address",
+	"step_image_url":           "URL of the image of the step",
+	"step_name":                "Name of the step",
+	"stream":                   "Print the items as JSON lines as they arrive, implies --all",
+	"street1":                  "First line of the billing address",
+	"street2":                  "Second line of the billing address",
+	"target_team":              "ID of the workspace that receives the invite",
+	"tax_id":                   "Tax ID of the business",
+	"team":                     "ID of the workspace",
+	"team_id":                  "ID of the workspace",
+	"team_ids":                 "Comma-separated workspace IDs",
+	"test":                     "Use \"test\" to check the token without revoking it",
+	"text":                     "Text of the message",
+	"thread-ts":                "Timestamp of the parent message, to reply in its thread",
+	"time":                     "Timestamp of the message",
+	"token":                    "Token of the workspace, like xoxp-...",
+	"topic":                    "New topic of the channel",
+	"ts":                       "Timestamp of the parent message of the thread",
+	"type":                     "Kind of file",
+	"unfurl-links":             "Show a preview of the links to text-based content",
+	"user":                     "User ID, @username, display name or email",
+	"user_ids":                 "Comma-separated users to invite",
+	"username":                 "New username",
+	"users":                    "Comma-separated user IDs, @usernames or emails",
+	"value":                    "Value",
+	"vat_id":                   "VAT number of the business",
+	"version_ts":               "Version timestamp of the client",
+	"waiting_for_vat":          "The VAT number is pending",
+	"workflow_step_edit_id":    "Context identifier from the workflow_step_edit event",
+	"workflow_step_execute_id": "Context identifier from the workflow_step_execute event",
+	"zip":                      "Postal code of the billing address",
+
+	"batch file":                       "File with one command per line, or - for the standard input",
+	"conversations.create name":        "Name of the new channel",
+	"conversations.history time":       "Timestamp of the most recent message to include",
+	"conversations.id room":            "Name of the channel",
+	"conversations.mark time":          "Timestamp of the most recently seen message",
+	"conversations.rename name":        "New name of the channel",
+	"eventlog.history time":            "Unix timestamp of the oldest event to include",
+	"files.listAfterTime time":         "Unix timestamp of the oldest file to include",
+	"files.listBeforeTime time":        "Unix timestamp of the most recent file to include",
+	"profile.add name":                 "Name of the profile",
+	"profile.add token":                "Token of the workspace, like xoxp-...",
+	"profile.remove name":              "Name of the profile",
+	"profile.use name":                 "Name of the profile",
+	"reactions.add name":               "Name of the emoji, without colons",
+	"reactions.remove name":            "Name of the emoji, without colons",
+	"search.users user":                "Part of the name of the users",
+	"team.integrationLogs user":        "User who made the change",
+	"users.id user":                    "Username",
+	"users.prefs.set name":             "Name of the preference",
+	"users.prefs.set value":            "Value of the preference",
+	"users.profile.set name":           "Name of the profile field, like title or status_text",
+	"users.profile.set value":          "Value of the profile field",
+	"users.setStatus text":             "Text of the status",
+	"workflows.stepFailed error":       "Message shown to the user about the failure",
+	"chat.postAttachment json":         "Attachment as a JSON object, with fields like text, color and title",
+	"chat.deleteAttachment attachment": "Position of the attachment in the message, starting at 1",
+	"chat.reply text":                  "Text of the reply",
+	"chat.postEphemeral user":          "User who sees the message, who must be in the channel",
+}
+
+// paramExample is the value used for a parameter in the generated examples.
+var paramExample = map[string]string{
+	"app_id":   "A0123456789",
+	"bot":      "B0123456789",
+	"channel":  "\"#general\"",
+	"channels": "\"#general,#random\"",
+	"email":    "alice@example.com",
+	"emoji":    ":coffee:",
+	"file":     "F0123456789",
+	"filename": "report.pdf",
+	"image":    "avatar.png",
+	"item_id":  "1650000000.123456",
+	"json":     "'{\"text\":\"Hello world\",\"color\":\"good\"}'",
+	"manifest": "@manifest.json",
+	"method":   "bookmarks.list",
+	"name":     "thumbsup",
+	"purpose":  "\"Deploy notifications\"",
+	"query":    "\"in:#general deploy\"",
+	"room":     "\"#general\"",
+	"team":     "T0123456789",
+	"team_id":  "T0123456789",
+	"text":     "\"Hello world\"",
+	"time":     "1650000000.123456",
+	"token":    "-",
+	"topic":    "\"Deploys at 10:00\"",
+	"ts":       "1650000000.123456",
+	"user":     "@alice",
+	"users":    "@alice,@bob",
+
+	"batch file": "commands.txt",
+	"call args":  "channel_id=C0123456789",
+	"chat.deleteScheduledMessage scheduled_message_id": "Q0123456789",
+	"chat.scheduleMessage post_at":                     "\"tomorrow 9:00\"",
+	"chat.reply permalink":                             "https://example.slack.com/archives/C0123456789/p1650000000123456",
+	"conversations.create name":                        "deploys",
+	"conversations.id room":                            "general",
+	"conversations.rename name":                        "deploys",
+	"profile.add name":                                 "work",
+	"profile.remove name":                              "work",
+	"profile.use name":                                 "work",
+	"users.id user":                                    "alice",
+	"users.prefs.set name":                             "emoji_mode",
+	"users.prefs.set value":                            "default",
+	"users.profile.set name":                           "title",
+	"users.profile.set value":                          "\"Site Reliability\"",
+	"search.users user":                                "alice",
+}
+
+// commandScopes lists the OAuth scopes that a token needs to use a command.
+var commandScopes = map[string]string{
+	"apps.connections.open":             "connections:write",
+	"apps.event.authorizations.list":    "authorizations:read",
+	"bots.info":                         "users:read",
+	"chat.delete":                       "chat:write",
+	"chat.deleteAttachment":             "chat:write",
+	"chat.deleteScheduledMessage":       "chat:write",
+	"chat.dm":                           "chat:write, im:write",
+	"chat.meMessage":                    "chat:write",
+	"chat.mpdm":                         "chat:write, mpim:write",
+	"chat.postAttachment":               "chat:write",
+	"chat.postEphemeral":                "chat:write",
+	"chat.postMessage":                  "chat:write",
+	"chat.reply":                        "chat:write",
+	"chat.robotMessage":                 "chat:write",
+	"chat.scheduleMessage":              "chat:write",
+	"chat.update":                       "chat:write",
+	"conversations.acceptSharedInvite":  "conversations.connect:write",
+	"conversations.approveSharedInvite": "conversations.connect:manage",
+	"conversations.archive":             "channels:write, groups:write, im:write, mpim:write",
+	"conversations.close":               "channels:write, groups:write, im:write, mpim:write",
+	"conversations.create":              "channels:write, groups:write, im:write, mpim:write",
+	"conversations.declineSharedInvite": "conversations.connect:manage",
+	"conversations.history":             "channels:history, groups:history, im:history, mpim:history",
+	"conversations.id":                  "search:read",
+	"conversations.info":                "channels:read, groups:read, im:read, mpim:read",
+	"conversations.invite":              "channels:write, groups:write, im:write, mpim:write",
+	"conversations.inviteShared":        "conversations.connect:write",
+	"conversations.join":                "channels:write",
+	"conversations.kick":                "channels:write, groups:write, im:write, mpim:write",
+	"conversations.leave":               "channels:write, groups:write, im:write, mpim:write",
+	"conversations.list":                "channels:read, groups:read, im:read, mpim:read",
+	"conversations.listConnectInvites":  "conversations.connect:manage",
+	"conversations.mark":                "channels:write, groups:write, im:write, mpim:write",
+	"conversations.members":             "channels:read, groups:read, im:read, mpim:read",
+	"conversations.open":                "channels:write, groups:write, im:write, mpim:write",
+	"conversations.rename":              "channels:write, groups:write, im:write, mpim:write",
+	"conversations.replies":             "channels:history, groups:history, im:history, mpim:history",
+	"conversations.setPurpose":          "channels:write, groups:write, im:write, mpim:write",
+	"conversations.setTopic":            "channels:write, groups:write, im:write, mpim:write",
+	"conversations.unarchive":           "channels:write, groups:write, im:write, mpim:write",
+	"dnd.endDnd":                        "dnd:write",
+	"dnd.endSnooze":                     "dnd:write",
+	"dnd.info":                          "dnd:read",
+	"dnd.setSnooze":                     "dnd:write",
+	"dnd.teamInfo":                      "dnd:read",
+	"emoji.list":                        "emoji:read",
+	"files.comments.add":                "files:write",
+	"files.comments.delete":             "files:write",
+	"files.comments.edit":               "files:write",
+	"files.delete":                      "files:write",
+	"files.info":                        "files:read",
+	"files.list":                        "files:read",
+	"files.listAfterTime":               "files:read",
+	"files.listBeforeTime":              "files:read",
+	"files.listByChannel":               "files:read",
+	"files.listByType":                  "files:read",
+	"files.listByUser":                  "files:read",
+	"files.revokePublicURL":             "files:write",
+	"files.sharedPublicURL":             "files:write",
+	"files.upload":                      "files:write",
+	"migration.exchange":                "tokens.basic",
+	"pins.add":                          "pins:write",
+	"pins.list":                         "pins:read",
+	"pins.remove":                       "pins:write",
+	"reactions.add":                     "reactions:write",
+	"reactions.get":                     "reactions:read",
+	"reactions.list":                    "reactions:read",
+	"reactions.remove":                  "reactions:write",
+	"search.all":                        "search:read",
+	"search.channels":                   "search:read",
+	"search.files":                      "search:read",
+	"search.messages":                   "search:read",
+	"search.modules":                    "search:read",
+	"stars.add":                         "stars:write",
+	"stars.list":                        "stars:read",
+	"stars.remove":                      "stars:write",
+	"team.accessLogs":                   "admin",
+	"team.billableInfo":                 "admin",
+	"team.billing.info":                 "team.billing:read",
+	"team.info":                         "team:read",
+	"team.integrationLogs":              "admin",
+	"team.preferences.list":             "team.preferences:read",
+	"team.profile.get":                  "users.profile:read",
+	"users.deletePhoto":                 "users.profile:write",
+	"users.getPresence":                 "users:read",
+	"users.id":                          "users:read",
+	"users.identity":                    "identity.basic",
+	"users.info":                        "users:read",
+	"users.list":                        "users:read",
+	"users.lookupByEmail":               "users:read.email",
+	"users.profile.get":                 "users.profile:read",
+	"users.profile.set":                 "users.profile:write",
+	"users.setActive":                   "users:write",
+	"users.setPhoto":                    "users.profile:write",
+	"users.setPresence":                 "users:write",
+	"users.setStatus":                   "users.profile:write",
+	"workflows.stepCompleted":           "workflow.steps:execute",
+	"workflows.stepFailed":              "workflow.steps:execute",
+	"workflows.updateStep":              "workflow.steps:execute",
+}
+
+// commandMethods maps the commands to the method of the web API service that
+// they use, when it has another name. An empty method means that the command
+// runs locally, so it has no documentation page.
+var commandMethods = map[string]string{
+	"auth.login":          "",
+	"auth.logout":         "",
+	"batch":               "",
+	"cache.clear":         "",
+	"cache.refresh":       "",
+	"call":                "",
+	"chat.dm":             "chat.postMessage",
+	"chat.mpdm":           "chat.postMessage",
+	"chat.postAttachment": "chat.postMessage",
+	"chat.reply":          "chat.postMessage",
+	"chat.robotMessage":   "chat.postMessage",
+	"completion":          "",
+	"conversations.id":    "search.modules",
+	"help":                "",
+	"man":                 "",
+	"profile.add":         "",
+	"profile.list":        "",
+	"profile.remove":      "",
+	"profile.use":         "",
+	"rtm.events":          "rtm.connect",
+	"shell":               "",
+	"users.id":            "users.list",
+	"users.setStatus":     "users.profile.set",
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// DefaultAPIURL is the address of the web API service.
+const DefaultAPIURL = "https://slack.com/api/"
+
+// EndpointTransport is an HTTP transport that sends the requests for the web
+// API service to another address, like the canonical endpoint of an Enterprise
+// organization, a recording proxy or a local server that mimics the service.
+// The WebSocket URL returned by rtm.connect and rtm.start is changed to point
+// to the same host, so the real-time messaging commands also use it.
+type EndpointTransport struct {
+	Base    http.RoundTripper
+	BaseURL *url.URL
+}
+
+// NewEndpointTransport returns a transport that sends the requests to apiURL.
+func NewEndpointTransport(base http.RoundTripper, apiURL string) (*EndpointTransport, error) {
+	target, err := url.Parse(apiURL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Scheme != "http" && target.Scheme != "https" || target.Host == "" {
+		return nil, fmt.Errorf("invalid API URL %q, use an address like %s", apiURL, DefaultAPIURL)
+	}
+
+	if !strings.HasSuffix(target.Path, "/") {
+		target.Path += "/"
+	}
+
+	return &EndpointTransport{Base: base, BaseURL: target}, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *EndpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isSlackHost(req.URL.Hostname()) || !strings.HasPrefix(req.URL.Path, "/api/") {
+		return t.Base.RoundTrip(req)
+	}
+
+	clone := req.Clone(req.Context())
+	clone.URL.Scheme = t.BaseURL.Scheme
+	clone.URL.Host = t.BaseURL.Host
+	clone.URL.Path = t.BaseURL.Path + strings.TrimPrefix(req.URL.Path, "/api/")
+	clone.URL.RawPath = ""
+	clone.Host = t.BaseURL.Host
+
+	res, err := t.Base.RoundTrip(clone)
+
+	if err != nil {
+		return res, err
+	}
+
+	switch path.Base(req.URL.Path) {
+	case "rtm.connect", "rtm.start":
+		return t.rewriteWebSocketURL(res)
+	}
+
+	return res, nil
+}
+
+// rewriteWebSocketURL changes the host of the WebSocket URL in the response of
+// rtm.connect and rtm.start to the host of the base URL, unless the server
+// already returned its own address.
+func (t *EndpointTransport) rewriteWebSocketURL(res *http.Response) (*http.Response, error) {
+	var data map[string]interface{}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&data); err != nil {
+		return res, nil
+	}
+
+	address, _ := data["url"].(string)
+	socket, err := url.Parse(address)
+
+	if address == "" || err != nil || !isSlackHost(socket.Hostname()) {
+		return res, nil
+	}
+
+	socket.Scheme = "wss"
+
+	if t.BaseURL.Scheme == "http" {
+		socket.Scheme = "ws"
+	}
+
+	socket.Host = t.BaseURL.Host
+	data["url"] = socket.String()
+
+	if body, err = json.Marshal(data); err != nil {
+		return nil, err
+	}
+
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	res.ContentLength = int64(len(body))
+	res.Header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	return res, nil
+}
+
+// isSlackHost returns true if the host belongs to the web API service.
+func isSlackHost(host string) bool {
+	return host == "slack.com" || strings.HasSuffix(host, ".slack.com")
+}
+
+// SetAPIURL sends the requests for the web API service to another address.
+// The address passed in the command line or the environment takes precedence
+// over the one in the active profile.
+func (cli *CLI) SetAPIURL(apiURL string) error {
+	if apiURL == "" {
+		apiURL = cli.profile.APIURL
+	}
+
+	if apiURL == "" || apiURL == DefaultAPIURL {
+		return nil
+	}
+
+	transport, err := NewEndpointTransport(http.DefaultTransport, apiURL)
+
+	if err != nil {
+		return err
+	}
+
+	http.DefaultTransport = transport
+
+	return nil
+}
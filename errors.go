@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Exit codes returned by the program. Scripts can rely on them to decide if
+// an operation is worth retrying, like after a network or rate limit error,
+// or if it requires human intervention, like after an authentication error.
+const (
+	// ExitSuccess means the operation succeeded.
+	ExitSuccess = 0
+	// ExitFailure means the web API service returned an unclassified error.
+	ExitFailure = 1
+	// ExitUsage means the command, a flag or a parameter is invalid.
+	ExitUsage = 2
+	// ExitAuth means the token is missing, invalid, expired or revoked.
+	ExitAuth = 3
+	// ExitNotFound means the channel, user, message or file does not exist.
+	ExitNotFound = 4
+	// ExitPermission means the token lacks the scopes for the operation.
+	ExitPermission = 5
+	// ExitRateLimit means the web API service rejected too many requests.
+	ExitRateLimit = 6
+	// ExitNetwork means the web API service could not be reached.
+	ExitNetwork = 7
+)
+
+// ErrorKind defines the category of an error.
+type ErrorKind string
+
+const (
+	ErrorFailure    ErrorKind = "failure"
+	ErrorUsage      ErrorKind = "usage"
+	ErrorAuth       ErrorKind = "auth"
+	ErrorNotFound   ErrorKind = "not_found"
+	ErrorPermission ErrorKind = "permission"
+	ErrorRateLimit  ErrorKind = "rate_limit"
+	ErrorNetwork    ErrorKind = "network"
+)
+
+// exitCodes maps every kind of error to the exit code of the program.
+var exitCodes = map[ErrorKind]int{
+	ErrorFailure:    ExitFailure,
+	ErrorUsage:      ExitUsage,
+	ErrorAuth:       ExitAuth,
+	ErrorNotFound:   ExitNotFound,
+	ErrorPermission: ExitPermission,
+	ErrorRateLimit:  ExitRateLimit,
+	ErrorNetwork:    ExitNetwork,
+}
+
+// errorKinds maps the error codes of the web API service to a kind of error.
+// Codes ending with "_not_found" are classified as ErrorNotFound.
+var errorKinds = map[string]ErrorKind{
+	"account_inactive":             ErrorAuth,
+	"invalid_auth":                 ErrorAuth,
+	"not_authed":                   ErrorAuth,
+	"token_expired":                ErrorAuth,
+	"token_revoked":                ErrorAuth,
+	"access_denied":                ErrorPermission,
+	"ekm_access_denied":            ErrorPermission,
+	"missing_scope":                ErrorPermission,
+	"no_permission":                ErrorPermission,
+	"not_allowed_token_type":       ErrorPermission,
+	"not_authorized":               ErrorPermission,
+	"not_in_channel":               ErrorPermission,
+	"restricted_action":            ErrorPermission,
+	"file_deleted":                 ErrorNotFound,
+	"invalid_scheduled_message_id": ErrorNotFound,
+	"user_not_visible":             ErrorNotFound,
+	"ratelimited":                  ErrorRateLimit,
+	"rate_limited":                 ErrorRateLimit,
+	"invalid_arguments":            ErrorUsage,
+	"invalid_arg_name":             ErrorUsage,
+	"invalid_array_arg":            ErrorUsage,
+	"invalid_charset":              ErrorUsage,
+	"invalid_form_data":            ErrorUsage,
+	"invalid_post_type":            ErrorUsage,
+	"missing_post_type":            ErrorUsage,
+	"unknown_method":               ErrorUsage,
+}
+
+// networkErrors contains fragments of the messages returned by the HTTP client
+// when the web API service cannot be reached.
+var networkErrors = []string{
+	"connection refused",
+	"connection reset",
+	"dial tcp",
+	"i/o timeout",
+	"no such host",
+	"network is unreachable",
+	"tls:",
+	"unexpected EOF",
+	"Client.Timeout",
+	"context deadline exceeded",
+}
+
+// Error defines a failure reported by the program or by the web API service.
+// Needed and Provided contain the OAuth scopes of a missing_scope error.
+type Error struct {
+	Code     string    `json:"error"`
+	Kind     ErrorKind `json:"kind"`
+	Command  string    `json:"command,omitempty"`
+	Needed   string    `json:"needed,omitempty"`
+	Provided string    `json:"provided,omitempty"`
+}
+
+// NewError returns an error of a specific kind.
+func NewError(kind ErrorKind, format string, a ...interface{}) *Error {
+	return &Error{Kind: kind, Code: fmt.Sprintf(format, a...)}
+}
+
+// UsageError returns an error caused by an invalid command, flag or parameter.
+func UsageError(format string, a ...interface{}) *Error {
+	return NewError(ErrorUsage, format, a...)
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Code
+}
+
+// ExitCode returns the exit code associated to the kind of error.
+func (e *Error) ExitCode() int {
+	if code, ok := exitCodes[e.Kind]; ok {
+		return code
+	}
+
+	return ExitFailure
+}
+
+// ClassifyError converts any error into an *Error, guessing its kind from the
+// error code returned by the web API service or the message of the client.
+func ClassifyError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+
+	return &Error{Kind: errorKind(err.Error()), Code: err.Error()}
+}
+
+// errorKind returns the kind of an error code. Errors generated by this
+// program are prefixed with the name of the operation and a semicolon, like
+// "conversations.open; channel_not_found", the prefix is ignored.
+func errorKind(code string) ErrorKind {
+	name := code
+
+	if i := strings.LastIndex(name, ";\x20"); i >= 0 {
+		name = name[i+2:]
+	}
+
+	if kind, ok := errorKinds[name]; ok {
+		return kind
+	}
+
+	if strings.HasSuffix(name, "_not_found") {
+		return ErrorNotFound
+	}
+
+	for _, fragment := range networkErrors {
+		if strings.Contains(code, fragment) {
+			return ErrorNetwork
+		}
+	}
+
+	return ErrorFailure
+}
+
+// responseError returns the error in a response from the web API service,
+// including the scopes of a missing_scope error.
+func (cli *CLI) responseError(out []byte) *Error {
+	var res struct {
+		Error    string `json:"error"`
+		Needed   string `json:"needed"`
+		Provided string `json:"provided"`
+	}
+
+	_ = json.NewDecoder(bytes.NewReader(out)).Decode(&res)
+
+	err := ClassifyError(fmt.Errorf("%s", res.Error))
+	err.Needed = res.Needed
+	err.Provided = res.Provided
+
+	return err
+}
+
+// PrintError writes an error as a JSON object to the standard error, so the
+// standard output only contains successful responses, and returns the exit
+// code associated to the kind of error.
+//
+//	{"ok":false,"error":"missing_scope","kind":"permission","needed":"chat:write","exit_code":5}
+func (cli *CLI) PrintError(err error) int {
+	e := ClassifyError(err)
+
+	if e.Command == "" {
+		e.Command = cli.command
+	}
+
+	out, _ := json.Marshal(struct {
+		Ok bool `json:"ok"`
+		*Error
+		ExitCode int `json:"exit_code"`
+	}{
+		Error:    e,
+		ExitCode: e.ExitCode(),
+	})
+
+	fmt.Fprintf(cli.stderr, "%s\n", out)
+
+	return e.ExitCode()
+}
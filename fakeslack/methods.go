@@ -0,0 +1,1583 @@
+package fakeslack
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// handlers maps the methods of the web API service used by slackcli to their
+// implementation in the fake server.
+var handlers = map[string]Handler{
+	"api.test":                    apiTest,
+	"auth.revoke":                 authRevoke,
+	"auth.teams.list":             authTeamsList,
+	"auth.test":                   authTest,
+	"bots.info":                   botsInfo,
+	"chat.delete":                 chatDelete,
+	"chat.deleteAttachment":       chatDeleteAttachment,
+	"chat.deleteScheduledMessage": chatDeleteScheduledMessage,
+	"chat.getPermalink":           chatGetPermalink,
+	"chat.meMessage":              chatMeMessage,
+	"chat.postEphemeral":          chatPostEphemeral,
+	"chat.postMessage":            chatPostMessage,
+	"chat.scheduleMessage":        chatScheduleMessage,
+	"chat.scheduledMessages.list": chatScheduledMessagesList,
+	"chat.update":                 chatUpdate,
+	"conversations.archive":       conversationsArchive,
+	"conversations.close":         conversationsClose,
+	"conversations.create":        conversationsCreate,
+	"conversations.delete":        conversationsDelete,
+	"conversations.genericInfo":   conversationsGenericInfo,
+	"conversations.history":       conversationsHistory,
+	"conversations.info":          conversationsInfo,
+	"conversations.invite":        conversationsInvite,
+	"conversations.join":          conversationsJoin,
+	"conversations.kick":          conversationsKick,
+	"conversations.leave":         conversationsLeave,
+	"conversations.list":          conversationsList,
+	"conversations.mark":          conversationsMark,
+	"conversations.members":       conversationsMembers,
+	"conversations.open":          conversationsOpen,
+	"conversations.rename":        conversationsRename,
+	"conversations.replies":       conversationsReplies,
+	"conversations.setPurpose":    conversationsSetPurpose,
+	"conversations.setTopic":      conversationsSetTopic,
+	"conversations.unarchive":     conversationsUnarchive,
+	"dnd.endDnd":                  dndEndSnooze,
+	"dnd.endSnooze":               dndEndSnooze,
+	"dnd.info":                    dndInfo,
+	"dnd.setSnooze":               dndSetSnooze,
+	"dnd.teamInfo":                dndTeamInfo,
+	"emoji.list":                  emojiList,
+	"files.comments.add":          filesCommentsAdd,
+	"files.comments.delete":       filesCommentsDelete,
+	"files.comments.edit":         filesCommentsEdit,
+	"files.delete":                filesDelete,
+	"files.info":                  filesInfo,
+	"files.list":                  filesList,
+	"files.revokePublicURL":       filesRevokePublicURL,
+	"files.sharedPublicURL":       filesSharedPublicURL,
+	"files.upload":                filesUpload,
+	"migration.exchange":          migrationExchange,
+	"pins.add":                    pinsAdd,
+	"pins.list":                   pinsList,
+	"pins.remove":                 pinsRemove,
+	"reactions.add":               reactionsAdd,
+	"reactions.get":               reactionsGet,
+	"reactions.list":              reactionsList,
+	"reactions.remove":            reactionsRemove,
+	"rtm.connect":                 rtmConnect,
+	"rtm.start":                   rtmConnect,
+	"search.all":                  searchAll,
+	"search.files":                searchFiles,
+	"search.messages":             searchMessages,
+	"search.modules":              searchModules,
+	"stars.add":                   starsAdd,
+	"stars.list":                  starsList,
+	"stars.remove":                starsRemove,
+	"team.billableInfo":           teamBillableInfo,
+	"team.channels.info":          teamChannelsInfo,
+	"team.channels.membership":    teamChannelsMembership,
+	"team.info":                   teamInfo,
+	"users.getPresence":           usersGetPresence,
+	"users.identity":              usersIdentity,
+	"users.info":                  usersInfo,
+	"users.list":                  usersList,
+	"users.lookupByEmail":         usersLookupByEmail,
+	"users.profile.get":           usersProfileGet,
+	"users.profile.set":           usersProfileSet,
+	"users.setPresence":           usersSetPresence,
+	"users.setStatus":             usersSetStatus,
+}
+
+// cannedResponses contains the answer of the methods that are not modelled by
+// the fake server, which always succeed.
+var cannedResponses = map[string]Response{
+	"api.getFlannelHttpUrl":                     {"url": "https://slack.com/api/"},
+	"apps.connections.open":                     {"url": "wss://wss-primary.slack.com/link/?ticket=fake"},
+	"apps.event.authorizations.list":            {"authorizations": []interface{}{}},
+	"apps.list":                                 {"apps": []interface{}{}},
+	"apps.manifest.create":                      {"app_id": "A00000001"},
+	"apps.manifest.delete":                      {},
+	"apps.manifest.export":                      {"manifest": map[string]interface{}{}},
+	"apps.manifest.update":                      {"app_id": "A00000001"},
+	"apps.manifest.validate":                    {},
+	"client.counts":                             {"channels": []interface{}{}, "ims": []interface{}{}, "mpims": []interface{}{}},
+	"client.shouldReload":                       {"should_reload": false},
+	"conversations.acceptSharedInvite":          {"implicit_approval": true},
+	"conversations.approveSharedInvite":         {},
+	"conversations.declineSharedInvite":         {},
+	"conversations.inviteShared":                {"invite_id": "I00000001"},
+	"conversations.listConnectInvites":          {"invites": []interface{}{}},
+	"conversations.suggestions":                 {"status": []interface{}{}},
+	"eventlog.history":                          {"events": []interface{}{}, "has_more": false},
+	"help.issues.list":                          {"issues": []interface{}{}},
+	"payments.billing.addresses.get":            {"addresses": []interface{}{}},
+	"payments.billing.addresses.validateAndSet": {},
+	"signup.checkEmail":                         {"is_valid": true},
+	"signup.confirmEmail":                       {},
+	"team.accessLogs":                           {"logins": []interface{}{}},
+	"team.billing.info":                         {"plan": "free"},
+	"team.integrationLogs":                      {"logs": []interface{}{}},
+	"team.listExternal":                         {"organizations": []interface{}{}},
+	"team.preferences.list":                     {"prefs": map[string]interface{}{}},
+	"team.profile.get":                          {"profile": map[string]interface{}{"fields": []interface{}{}}},
+	"users.counts":                              {"channels": []interface{}{}},
+	"users.deletePhoto":                         {},
+	"users.prefs.get":                           {"prefs": map[string]interface{}{}},
+	"users.prefs.set":                           {},
+	"users.preparePhoto":                        {"id": "P00000001"},
+	"users.setActive":                           {},
+	"users.setAvatar":                           {},
+	"users.setEmail":                            {},
+	"users.setPhoto":                            {},
+	"users.setUsername":                         {},
+	"workflows.stepCompleted":                   {},
+	"workflows.stepFailed":                      {},
+	"workflows.updateStep":                      {},
+}
+
+// canned returns a handler that always answers with the same fields.
+func canned(fields Response) Handler {
+	return func(s *Server, r *Request) Response {
+		res := ok()
+
+		for key, value := range fields {
+			res[key] = value
+		}
+
+		return res
+	}
+}
+
+func apiTest(s *Server, r *Request) Response {
+	if code := r.Args["error"]; code != "" {
+		res := fail(code)
+		res["args"] = r.Args
+		return res
+	}
+
+	return ok("args", r.Args)
+}
+
+func authRevoke(s *Server, r *Request) Response {
+	return ok("revoked", !r.Bool("test"))
+}
+
+func authTest(s *Server, r *Request) Response {
+	w := s.Workspace
+
+	return ok(
+		"url", "https://"+w.Domain+".slack.com/",
+		"team", w.TeamName,
+		"user", r.User.Name,
+		"team_id", w.TeamID,
+		"user_id", r.User.ID,
+	)
+}
+
+func authTeamsList(s *Server, r *Request) Response {
+	w := s.Workspace
+
+	return ok("teams", []interface{}{
+		map[string]interface{}{"id": w.TeamID, "name": w.TeamName, "domain": w.Domain},
+	})
+}
+
+func botsInfo(s *Server, r *Request) Response {
+	for _, user := range s.Workspace.Users {
+		if user.IsBot && (r.Args["bot"] == "" || r.Args["bot"] == user.ID) {
+			return ok("bot", map[string]interface{}{"id": user.ID, "name": user.Name, "deleted": false})
+		}
+	}
+
+	return fail("bot_not_found")
+}
+
+func teamInfo(s *Server, r *Request) Response {
+	w := s.Workspace
+
+	return ok("team", map[string]interface{}{"id": w.TeamID, "name": w.TeamName, "domain": w.Domain})
+}
+
+// writableChannel returns the channel where the user is trying to post, or
+// the error code explaining why it is not possible.
+func writableChannel(s *Server, r *Request, name string) (*Channel, string) {
+	id := r.Args[name]
+
+	if id == "" {
+		return nil, "channel_not_found"
+	}
+
+	channel := s.Workspace.Channel(id)
+
+	if channel == nil {
+		channel = s.Workspace.ChannelByName(strings.TrimPrefix(id, "#"))
+	}
+
+	if channel == nil {
+		return nil, "channel_not_found"
+	}
+
+	if channel.IsArchived {
+		return nil, "is_archived"
+	}
+
+	if channel.IsPrivate && !channel.IsMember(r.User.ID) {
+		return nil, "channel_not_found"
+	}
+
+	return channel, ""
+}
+
+func chatPostMessage(s *Server, r *Request) Response {
+	channel, code := writableChannel(s, r, "channel")
+
+	if code != "" {
+		return fail(code)
+	}
+
+	msg := &Message{
+		Channel:  channel.ID,
+		User:     r.User.ID,
+		Text:     r.Args["text"],
+		ThreadTs: r.Args["thread_ts"],
+	}
+
+	if r.Args["username"] != "" {
+		msg.Subtype = "bot_message"
+		msg.Username = r.Args["username"]
+	}
+
+	if raw := r.Args["attachments"]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &msg.Attachments); err != nil {
+			return fail("invalid_attachments")
+		}
+	}
+
+	if raw := r.Args["blocks"]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &msg.Blocks); err != nil {
+			return fail("invalid_blocks")
+		}
+	}
+
+	if msg.Text == "" && msg.Attachments == nil && msg.Blocks == nil {
+		return fail("no_text")
+	}
+
+	if msg.ThreadTs != "" && s.Workspace.Message(channel.ID, msg.ThreadTs) == nil {
+		return fail("thread_not_found")
+	}
+
+	s.Workspace.Post(msg)
+
+	return ok("channel", channel.ID, "ts", msg.Ts, "message", msg)
+}
+
+func chatMeMessage(s *Server, r *Request) Response {
+	channel, code := writableChannel(s, r, "channel")
+
+	if code != "" {
+		return fail(code)
+	}
+
+	msg := s.Workspace.Post(&Message{Channel: channel.ID, User: r.User.ID, Subtype: "me_message", Text: r.Args["text"]})
+
+	return ok("channel", channel.ID, "ts", msg.Ts)
+}
+
+// chatPostEphemeral checks that the message could be shown to the user, but
+// does not keep it because nobody else can read it.
+func chatPostEphemeral(s *Server, r *Request) Response {
+	channel, code := writableChannel(s, r, "channel")
+
+	if code != "" {
+		return fail(code)
+	}
+
+	if s.Workspace.User(r.Args["user"]) == nil {
+		return fail("user_not_found")
+	}
+
+	if !channel.IsMember(r.Args["user"]) {
+		return fail("user_not_in_channel")
+	}
+
+	if r.Args["text"] == "" && r.Args["blocks"] == "" {
+		return fail("no_text")
+	}
+
+	return ok("message_ts", s.Workspace.NextTs())
+}
+
+func chatScheduleMessage(s *Server, r *Request) Response {
+	channel, code := writableChannel(s, r, "channel")
+
+	if code != "" {
+		return fail(code)
+	}
+
+	postAt, _ := strconv.ParseInt(r.Args["post_at"], 10, 64)
+
+	if postAt <= s.Workspace.Now() {
+		return fail("time_in_past")
+	}
+
+	msg := &Message{
+		Channel:  channel.ID,
+		User:     r.User.ID,
+		Text:     r.Args["text"],
+		ThreadTs: r.Args["thread_ts"],
+		PostAt:   postAt,
+	}
+
+	if raw := r.Args["blocks"]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &msg.Blocks); err != nil {
+			return fail("invalid_blocks")
+		}
+	}
+
+	if msg.Text == "" && msg.Blocks == nil {
+		return fail("no_text")
+	}
+
+	s.Workspace.Schedule(msg)
+
+	return ok("channel", channel.ID, "scheduled_message_id", msg.ScheduleID, "post_at", postAt, "message", msg)
+}
+
+func chatScheduledMessagesList(s *Server, r *Request) Response {
+	list := []interface{}{}
+
+	for _, msg := range s.Workspace.Scheduled(r.Args["channel"]) {
+		list = append(list, map[string]interface{}{
+			"id":           msg.ScheduleID,
+			"channel_id":   msg.Channel,
+			"post_at":      msg.PostAt,
+			"date_created": s.Workspace.Now(),
+			"text":         msg.Text,
+		})
+	}
+
+	start, end, meta := cursorPage(r, len(list), 100)
+
+	return ok("scheduled_messages", list[start:end], "response_metadata", meta)
+}
+
+func chatDeleteScheduledMessage(s *Server, r *Request) Response {
+	if !s.Workspace.Unschedule(r.Args["channel"], r.Args["scheduled_message_id"]) {
+		return fail("invalid_scheduled_message_id")
+	}
+
+	return ok()
+}
+
+func chatUpdate(s *Server, r *Request) Response {
+	msg := s.Workspace.Message(r.Args["channel"], r.Args["ts"])
+
+	if msg == nil {
+		return fail("message_not_found")
+	}
+
+	if msg.User != r.User.ID {
+		return fail("cant_update_message")
+	}
+
+	msg.Text = r.Args["text"]
+	msg.Edited = &Edited{User: r.User.ID, Ts: s.Workspace.NextTs()}
+
+	return ok("channel", msg.Channel, "ts", msg.Ts, "text", msg.Text)
+}
+
+func chatDelete(s *Server, r *Request) Response {
+	if !s.Workspace.DeleteMessage(r.Args["channel"], r.Args["ts"]) {
+		return fail("message_not_found")
+	}
+
+	return ok("channel", r.Args["channel"], "ts", r.Args["ts"])
+}
+
+func chatGetPermalink(s *Server, r *Request) Response {
+	msg := s.Workspace.Message(r.Args["channel"], r.Args["message_ts"])
+
+	if msg == nil {
+		return fail("message_not_found")
+	}
+
+	link := "https://" + s.Workspace.Domain + ".slack.com/archives/" + msg.Channel + "/p" + strings.Replace(msg.Ts, ".", "", 1)
+
+	if msg.ThreadTs != "" && msg.ThreadTs != msg.Ts {
+		link += "?thread_ts=" + msg.ThreadTs + "&cid=" + msg.Channel
+	}
+
+	return ok("channel", msg.Channel, "permalink", link)
+}
+
+func chatDeleteAttachment(s *Server, r *Request) Response {
+	msg := s.Workspace.Message(r.Args["channel"], r.Args["ts"])
+
+	if msg == nil {
+		return fail("message_not_found")
+	}
+
+	index := r.Int("attachment", 1) - 1
+
+	if index < 0 || index >= len(msg.Attachments) {
+		return fail("attachment_not_found")
+	}
+
+	msg.Attachments = append(msg.Attachments[:index], msg.Attachments[index+1:]...)
+
+	return ok("channel", msg.Channel, "ts", msg.Ts)
+}
+
+// readableChannel returns the channel the user wants to read.
+func readableChannel(s *Server, r *Request) (*Channel, Response) {
+	channel := s.Workspace.Channel(r.Args["channel"])
+
+	if channel == nil || (channel.IsPrivate && !channel.IsMember(r.User.ID)) {
+		return nil, fail("channel_not_found")
+	}
+
+	return channel, nil
+}
+
+func conversationsList(s *Server, r *Request) Response {
+	var list []*Channel
+
+	types := map[string]bool{}
+
+	for _, name := range r.List("types") {
+		types[name] = true
+	}
+
+	if len(types) == 0 {
+		types["public_channel"] = true
+	}
+
+	for _, channel := range s.Workspace.Channels {
+		if channel.IsArchived && r.Bool("exclude_archived") {
+			continue
+		}
+
+		switch {
+		case channel.IsIM:
+			if !types["im"] {
+				continue
+			}
+		case channel.IsMpIM:
+			if !types["mpim"] {
+				continue
+			}
+		case channel.IsPrivate:
+			if !types["private_channel"] || !channel.IsMember(r.User.ID) {
+				continue
+			}
+		default:
+			if !types["public_channel"] {
+				continue
+			}
+		}
+
+		list = append(list, channel)
+	}
+
+	start, end, meta := cursorPage(r, len(list), 100)
+
+	return ok("channels", list[start:end], "response_metadata", meta)
+}
+
+func conversationsInfo(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	return ok("channel", channel)
+}
+
+func conversationsGenericInfo(s *Server, r *Request) Response {
+	var list []*Channel
+
+	for _, id := range r.List("channel_ids") {
+		if channel := s.Workspace.Channel(id); channel != nil {
+			list = append(list, channel)
+		}
+	}
+
+	return ok("channels", list)
+}
+
+func conversationsHistory(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	var list []*Message
+
+	for _, msg := range s.Workspace.History(channel.ID) {
+		if latest := r.Args["latest"]; latest != "" && msg.Ts > latest {
+			continue
+		}
+
+		if oldest := r.Args["oldest"]; oldest != "" && msg.Ts < oldest {
+			continue
+		}
+
+		list = append(list, msg)
+	}
+
+	start, end, meta := cursorPage(r, len(list), 100)
+
+	return ok("messages", list[start:end], "has_more", end < len(list), "response_metadata", meta)
+}
+
+func conversationsReplies(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	list := s.Workspace.Replies(channel.ID, r.Args["ts"])
+
+	if len(list) == 0 {
+		return fail("thread_not_found")
+	}
+
+	start, end, meta := cursorPage(r, len(list), 1000)
+
+	return ok("messages", list[start:end], "has_more", end < len(list), "response_metadata", meta)
+}
+
+func conversationsMembers(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	start, end, meta := cursorPage(r, len(channel.Members), 100)
+
+	return ok("members", channel.Members[start:end], "response_metadata", meta)
+}
+
+func conversationsCreate(s *Server, r *Request) Response {
+	name := r.Args["name"]
+
+	if name == "" {
+		return fail("invalid_name_required")
+	}
+
+	if s.Workspace.ChannelByName(name) != nil {
+		return fail("name_taken")
+	}
+
+	channel := &Channel{
+		Name:      name,
+		IsChannel: !r.Bool("is_private"),
+		IsGroup:   r.Bool("is_private"),
+		IsPrivate: r.Bool("is_private"),
+		Creator:   r.User.ID,
+		Created:   s.Workspace.Now(),
+		Members:   []string{r.User.ID},
+	}
+
+	prefix := "C"
+
+	if channel.IsPrivate {
+		prefix = "G"
+	}
+
+	channel.ID = s.Workspace.NextID(prefix)
+	channel.NumMembers = 1
+	s.Workspace.Channels = append(s.Workspace.Channels, channel)
+
+	return ok("channel", channel)
+}
+
+func conversationsRename(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	if s.Workspace.ChannelByName(r.Args["name"]) != nil {
+		return fail("name_taken")
+	}
+
+	channel.Name = r.Args["name"]
+
+	return ok("channel", channel)
+}
+
+func conversationsArchive(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	if channel.IsArchived {
+		return fail("already_archived")
+	}
+
+	channel.IsArchived = true
+
+	return ok()
+}
+
+func conversationsUnarchive(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	if !channel.IsArchived {
+		return fail("not_archived")
+	}
+
+	channel.IsArchived = false
+
+	return ok()
+}
+
+func conversationsDelete(s *Server, r *Request) Response {
+	for i, channel := range s.Workspace.Channels {
+		if channel.ID == r.Args["channel"] {
+			s.Workspace.Channels = append(s.Workspace.Channels[:i], s.Workspace.Channels[i+1:]...)
+			return ok()
+		}
+	}
+
+	return fail("channel_not_found")
+}
+
+func conversationsJoin(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	if channel.IsArchived {
+		return fail("is_archived")
+	}
+
+	channel.AddMember(r.User.ID)
+
+	return ok("channel", channel)
+}
+
+func conversationsLeave(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	if !channel.IsMember(r.User.ID) {
+		return ok("not_in_channel", true)
+	}
+
+	channel.RemoveMember(r.User.ID)
+
+	return ok()
+}
+
+func conversationsInvite(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	for _, id := range r.List("users") {
+		if s.Workspace.User(id) == nil {
+			return fail("user_not_found")
+		}
+
+		channel.AddMember(id)
+	}
+
+	return ok("channel", channel)
+}
+
+func conversationsKick(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	if !channel.IsMember(r.Args["user"]) {
+		return fail("not_in_channel")
+	}
+
+	channel.RemoveMember(r.Args["user"])
+
+	return ok()
+}
+
+func conversationsMark(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	channel.LastRead = r.Args["ts"]
+
+	return ok()
+}
+
+func conversationsSetTopic(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	channel.Topic = Purpose{Value: r.Args["topic"], Creator: r.User.ID, LastSet: s.Workspace.Now()}
+
+	return ok("channel", channel)
+}
+
+func conversationsSetPurpose(s *Server, r *Request) Response {
+	channel, res := readableChannel(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	channel.Purpose = Purpose{Value: r.Args["purpose"], Creator: r.User.ID, LastSet: s.Workspace.Now()}
+
+	return ok("channel", channel)
+}
+
+func conversationsOpen(s *Server, r *Request) Response {
+	if id := r.Args["channel"]; id != "" {
+		channel := s.Workspace.Channel(id)
+
+		if channel == nil || !channel.IsIM && !channel.IsMpIM {
+			return fail("channel_not_found")
+		}
+
+		return ok("channel", channel, "already_open", true)
+	}
+
+	users := r.List("users")
+
+	if len(users) == 0 {
+		return fail("users_list_not_supplied")
+	}
+
+	for _, id := range users {
+		if s.Workspace.User(id) == nil {
+			return fail("user_not_found")
+		}
+	}
+
+	members := append([]string{r.User.ID}, users...)
+	sort.Strings(members)
+
+	for _, channel := range s.Workspace.Channels {
+		if (channel.IsIM || channel.IsMpIM) && strings.Join(channel.Members, ",") == strings.Join(members, ",") {
+			return ok("channel", channel, "already_open", true)
+		}
+	}
+
+	if r.Bool("prevent_creation") {
+		return fail("channel_not_found")
+	}
+
+	channel := &Channel{IsIM: len(users) == 1, IsMpIM: len(users) > 1, IsPrivate: true, Creator: r.User.ID, Created: s.Workspace.Now(), Members: members}
+	channel.NumMembers = len(members)
+
+	if channel.IsIM {
+		channel.ID = s.Workspace.NextID("D")
+		channel.User = users[0]
+	} else {
+		channel.ID = s.Workspace.NextID("G")
+		channel.Name = "mpdm-" + strings.Join(members, "--")
+	}
+
+	s.Workspace.Channels = append(s.Workspace.Channels, channel)
+
+	return ok("channel", channel)
+}
+
+func conversationsClose(s *Server, r *Request) Response {
+	channel := s.Workspace.Channel(r.Args["channel"])
+
+	if channel == nil {
+		return fail("channel_not_found")
+	}
+
+	if !channel.IsIM && !channel.IsMpIM {
+		return fail("method_not_supported_for_channel_type")
+	}
+
+	return ok()
+}
+
+func dndInfo(s *Server, r *Request) Response {
+	user := r.Args["user"]
+
+	if user == "" {
+		user = r.User.ID
+	}
+
+	until, snoozed := s.Workspace.Snooze[user]
+
+	res := ok("dnd_enabled", snoozed, "snooze_enabled", snoozed)
+
+	if snoozed {
+		res["snooze_endtime"] = until
+	}
+
+	return res
+}
+
+func dndSetSnooze(s *Server, r *Request) Response {
+	minutes := r.Int("num_minutes", 0)
+
+	if minutes <= 0 {
+		return fail("invalid_minutes")
+	}
+
+	until := s.Workspace.Now() + int64(minutes*60)
+	s.Workspace.Snooze[r.User.ID] = until
+
+	return ok("snooze_enabled", true, "snooze_endtime", until)
+}
+
+func dndEndSnooze(s *Server, r *Request) Response {
+	if _, snoozed := s.Workspace.Snooze[r.User.ID]; !snoozed {
+		return fail("snooze_not_active")
+	}
+
+	delete(s.Workspace.Snooze, r.User.ID)
+
+	return ok("dnd_enabled", false, "snooze_enabled", false)
+}
+
+func dndTeamInfo(s *Server, r *Request) Response {
+	users := map[string]interface{}{}
+
+	for _, user := range s.Workspace.Users {
+		_, snoozed := s.Workspace.Snooze[user.ID]
+		users[user.ID] = map[string]interface{}{"dnd_enabled": snoozed}
+	}
+
+	return ok("users", users)
+}
+
+func emojiList(s *Server, r *Request) Response {
+	return ok("emoji", s.Workspace.Emoji)
+}
+
+func filesUpload(s *Server, r *Request) Response {
+	var content []byte
+
+	for _, data := range r.Files {
+		content = data
+	}
+
+	if content == nil {
+		content = []byte(r.Args["content"])
+	}
+
+	name := r.Args["filename"]
+
+	if name == "" {
+		return fail("no_file_data")
+	}
+
+	file := &File{
+		ID:       s.Workspace.NextID("F"),
+		Created:  s.Workspace.Now(),
+		Name:     name,
+		Title:    r.Args["title"],
+		Filetype: strings.TrimPrefix(filepath.Ext(name), "."),
+		Size:     len(content),
+		User:     r.User.ID,
+		Channels: r.List("channels"),
+		Content:  content,
+	}
+
+	if file.Title == "" {
+		file.Title = name
+	}
+
+	file.Permalink = "https://" + s.Workspace.Domain + ".slack.com/files/" + r.User.ID + "/" + file.ID + "/" + name
+	s.Workspace.Files = append(s.Workspace.Files, file)
+
+	return ok("file", file)
+}
+
+func filesInfo(s *Server, r *Request) Response {
+	file := s.Workspace.File(r.Args["file"])
+
+	if file == nil {
+		return fail("file_not_found")
+	}
+
+	return ok("file", file, "comments", file.Comments)
+}
+
+func filesList(s *Server, r *Request) Response {
+	var list []*File
+
+	for _, file := range s.Workspace.Files {
+		if user := r.Args["user"]; user != "" && file.User != user {
+			continue
+		}
+
+		if channel := r.Args["channel"]; channel != "" && !contains(file.Channels, channel) {
+			continue
+		}
+
+		if types := r.List("types"); len(types) > 0 && !contains(types, "all") && !contains(types, file.Filetype) {
+			continue
+		}
+
+		if from := r.Int("ts_from", 0); from > 0 && file.Created < int64(from) {
+			continue
+		}
+
+		if to := r.Int("ts_to", 0); to > 0 && file.Created > int64(to) {
+			continue
+		}
+
+		list = append(list, file)
+	}
+
+	start, end, paging := numberedPage(r, len(list))
+
+	return ok("files", list[start:end], "paging", paging)
+}
+
+func filesDelete(s *Server, r *Request) Response {
+	for i, file := range s.Workspace.Files {
+		if file.ID == r.Args["file"] {
+			s.Workspace.Files = append(s.Workspace.Files[:i], s.Workspace.Files[i+1:]...)
+			return ok()
+		}
+	}
+
+	return fail("file_not_found")
+}
+
+func filesSharedPublicURL(s *Server, r *Request) Response {
+	file := s.Workspace.File(r.Args["file"])
+
+	if file == nil {
+		return fail("file_not_found")
+	}
+
+	file.IsPublic = true
+	file.PublicURL = true
+
+	return ok("file", file)
+}
+
+func filesRevokePublicURL(s *Server, r *Request) Response {
+	file := s.Workspace.File(r.Args["file"])
+
+	if file == nil {
+		return fail("file_not_found")
+	}
+
+	file.PublicURL = false
+
+	return ok("file", file)
+}
+
+func filesCommentsAdd(s *Server, r *Request) Response {
+	file := s.Workspace.File(r.Args["file"])
+
+	if file == nil {
+		return fail("file_not_found")
+	}
+
+	comment := &Comment{ID: s.Workspace.NextID("Fc"), Created: s.Workspace.Now(), User: r.User.ID, Comment: r.Args["comment"]}
+	file.Comments = append(file.Comments, comment)
+	file.NumComment = len(file.Comments)
+
+	return ok("comment", comment)
+}
+
+func filesCommentsEdit(s *Server, r *Request) Response {
+	file := s.Workspace.File(r.Args["file"])
+
+	if file == nil {
+		return fail("file_not_found")
+	}
+
+	for _, comment := range file.Comments {
+		if comment.ID == r.Args["id"] {
+			comment.Comment = r.Args["comment"]
+			return ok("comment", comment)
+		}
+	}
+
+	return fail("comment_not_found")
+}
+
+func filesCommentsDelete(s *Server, r *Request) Response {
+	file := s.Workspace.File(r.Args["file"])
+
+	if file == nil {
+		return fail("file_not_found")
+	}
+
+	for i, comment := range file.Comments {
+		if comment.ID == r.Args["id"] {
+			file.Comments = append(file.Comments[:i], file.Comments[i+1:]...)
+			file.NumComment = len(file.Comments)
+			return ok()
+		}
+	}
+
+	return fail("comment_not_found")
+}
+
+// findItem returns the message or file referenced by the arguments of the
+// pins, reactions and stars methods.
+func findItem(s *Server, r *Request) (*Item, Response) {
+	if id := r.Args["file"]; id != "" {
+		file := s.Workspace.File(id)
+
+		if file == nil {
+			return nil, fail("file_not_found")
+		}
+
+		return &Item{Type: "file", File: file}, nil
+	}
+
+	ts := r.Args["timestamp"]
+
+	if ts == "" {
+		ts = r.Args["ts"]
+	}
+
+	if ts == "" {
+		ts = r.Args["item_id"]
+	}
+
+	if ts == "" {
+		return nil, fail("no_item_specified")
+	}
+
+	if strings.HasPrefix(ts, "F") {
+		r.Args["file"] = ts
+		return findItem(s, r)
+	}
+
+	msg := s.Workspace.Message(r.Args["channel"], ts)
+
+	if msg == nil {
+		return nil, fail("message_not_found")
+	}
+
+	return &Item{Type: "message", Channel: msg.Channel, Message: msg}, nil
+}
+
+// sameItem returns true if both items reference the same message or file.
+func sameItem(a *Item, b *Item) bool {
+	if a.File != nil || b.File != nil {
+		return a.File == b.File
+	}
+
+	return a.Message == b.Message
+}
+
+func pinsAdd(s *Server, r *Request) Response {
+	item, res := findItem(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	for _, pin := range s.Workspace.Pins {
+		if sameItem(pin, item) {
+			return fail("already_pinned")
+		}
+	}
+
+	item.Created = s.Workspace.Now()
+	s.Workspace.Pins = append(s.Workspace.Pins, item)
+
+	return ok()
+}
+
+func pinsList(s *Server, r *Request) Response {
+	list := []*Item{}
+
+	for _, pin := range s.Workspace.Pins {
+		if pin.Channel == r.Args["channel"] || pin.File != nil && contains(pin.File.Channels, r.Args["channel"]) {
+			list = append(list, pin)
+		}
+	}
+
+	return ok("items", list)
+}
+
+func pinsRemove(s *Server, r *Request) Response {
+	item, res := findItem(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	for i, pin := range s.Workspace.Pins {
+		if sameItem(pin, item) {
+			s.Workspace.Pins = append(s.Workspace.Pins[:i], s.Workspace.Pins[i+1:]...)
+			return ok()
+		}
+	}
+
+	return fail("no_pin")
+}
+
+func starsAdd(s *Server, r *Request) Response {
+	item, res := findItem(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	for _, star := range s.Workspace.Stars {
+		if sameItem(star, item) {
+			return fail("already_starred")
+		}
+	}
+
+	item.Created = s.Workspace.Now()
+	s.Workspace.Stars = append(s.Workspace.Stars, item)
+
+	return ok()
+}
+
+func starsList(s *Server, r *Request) Response {
+	start, end, paging := numberedPage(r, len(s.Workspace.Stars))
+
+	return ok("items", s.Workspace.Stars[start:end], "paging", paging)
+}
+
+func starsRemove(s *Server, r *Request) Response {
+	item, res := findItem(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	for i, star := range s.Workspace.Stars {
+		if sameItem(star, item) {
+			s.Workspace.Stars = append(s.Workspace.Stars[:i], s.Workspace.Stars[i+1:]...)
+			return ok()
+		}
+	}
+
+	return fail("not_starred")
+}
+
+func reactionsAdd(s *Server, r *Request) Response {
+	item, res := findItem(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	var added bool
+
+	if item.File != nil {
+		item.File.Reactions, added = addReaction(item.File.Reactions, r.Args["name"], r.User.ID)
+	} else {
+		item.Message.Reactions, added = addReaction(item.Message.Reactions, r.Args["name"], r.User.ID)
+	}
+
+	if !added {
+		return fail("already_reacted")
+	}
+
+	return ok()
+}
+
+func reactionsRemove(s *Server, r *Request) Response {
+	item, res := findItem(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	var removed bool
+
+	if item.File != nil {
+		item.File.Reactions, removed = removeReaction(item.File.Reactions, r.Args["name"], r.User.ID)
+	} else {
+		item.Message.Reactions, removed = removeReaction(item.Message.Reactions, r.Args["name"], r.User.ID)
+	}
+
+	if !removed {
+		return fail("no_reaction")
+	}
+
+	return ok()
+}
+
+func reactionsGet(s *Server, r *Request) Response {
+	item, res := findItem(s, r)
+
+	if res != nil {
+		return res
+	}
+
+	if item.File != nil {
+		return ok("type", "file", "file", item.File)
+	}
+
+	return ok("type", "message", "channel", item.Channel, "message", item.Message)
+}
+
+func reactionsList(s *Server, r *Request) Response {
+	user := r.Args["user"]
+
+	if user == "" {
+		user = r.User.ID
+	}
+
+	list := []*Item{}
+
+	for _, msg := range s.Workspace.Messages {
+		for _, reaction := range msg.Reactions {
+			if contains(reaction.Users, user) {
+				list = append(list, &Item{Type: "message", Channel: msg.Channel, Message: msg})
+				break
+			}
+		}
+	}
+
+	start, end, paging := numberedPage(r, len(list))
+
+	return ok("items", list[start:end], "paging", paging)
+}
+
+func rtmConnect(s *Server, r *Request) Response {
+	w := s.Workspace
+
+	return ok(
+		"url", "wss://wss-primary.slack.com/websocket/fake",
+		"team", map[string]interface{}{"id": w.TeamID, "name": w.TeamName, "domain": w.Domain},
+		"self", map[string]interface{}{"id": r.User.ID, "name": r.User.Name},
+	)
+}
+
+// matchMessages returns the messages that contain every word of the query.
+// The in:#channel and from:@user modifiers are supported.
+func matchMessages(s *Server, query string) []map[string]interface{} {
+	var words []string
+	var channel, user string
+
+	for _, word := range strings.Fields(query) {
+		switch {
+		case strings.HasPrefix(word, "in:"):
+			channel = strings.TrimPrefix(strings.TrimPrefix(word, "in:"), "#")
+		case strings.HasPrefix(word, "from:"):
+			user = strings.TrimPrefix(strings.TrimPrefix(word, "from:"), "@")
+		default:
+			words = append(words, strings.ToLower(word))
+		}
+	}
+
+	matches := []map[string]interface{}{}
+
+	for _, msg := range s.Workspace.Messages {
+		conversation := s.Workspace.Channel(msg.Channel)
+
+		if conversation == nil || conversation.IsPrivate || msg.PostAt != 0 {
+			continue
+		}
+
+		if channel != "" && conversation.Name != channel && conversation.ID != channel {
+			continue
+		}
+
+		author := s.Workspace.User(msg.User)
+
+		if user != "" && (author == nil || author.Name != user && author.ID != user) {
+			continue
+		}
+
+		found := true
+
+		for _, word := range words {
+			if !strings.Contains(strings.ToLower(msg.Text), word) {
+				found = false
+				break
+			}
+		}
+
+		if !found {
+			continue
+		}
+
+		username := ""
+
+		if author != nil {
+			username = author.Name
+		}
+
+		matches = append(matches, map[string]interface{}{
+			"type":     "message",
+			"ts":       msg.Ts,
+			"user":     msg.User,
+			"username": username,
+			"text":     msg.Text,
+			"channel":  map[string]interface{}{"id": conversation.ID, "name": conversation.Name},
+		})
+	}
+
+	return matches
+}
+
+func matchFiles(s *Server, query string) []*File {
+	list := []*File{}
+
+	for _, file := range s.Workspace.Files {
+		if strings.Contains(strings.ToLower(file.Name+" "+file.Title), strings.ToLower(query)) {
+			list = append(list, file)
+		}
+	}
+
+	return list
+}
+
+func searchMessages(s *Server, r *Request) Response {
+	matches := matchMessages(s, r.Args["query"])
+	start, end, paging := numberedPage(r, len(matches))
+	paging["total"] = len(matches)
+
+	return ok("query", r.Args["query"], "messages", map[string]interface{}{
+		"total":   len(matches),
+		"matches": matches[start:end],
+		"paging":  paging,
+	})
+}
+
+func searchFiles(s *Server, r *Request) Response {
+	matches := matchFiles(s, r.Args["query"])
+	start, end, paging := numberedPage(r, len(matches))
+
+	return ok("query", r.Args["query"], "files", map[string]interface{}{
+		"total":   len(matches),
+		"matches": matches[start:end],
+		"paging":  paging,
+	})
+}
+
+func searchAll(s *Server, r *Request) Response {
+	messages := searchMessages(s, r)
+	files := searchFiles(s, r)
+
+	return ok("query", r.Args["query"], "messages", messages["messages"], "files", files["files"])
+}
+
+func searchModules(s *Server, r *Request) Response {
+	query := strings.ToLower(r.Args["query"])
+
+	switch r.Args["module"] {
+	case "channels":
+		items := []map[string]interface{}{}
+
+		for _, channel := range s.Workspace.Channels {
+			if channel.Name != "" && !channel.IsPrivate && strings.Contains(channel.Name, query) {
+				items = append(items, map[string]interface{}{"id": channel.ID, "name": channel.Name})
+			}
+		}
+
+		start, end, paging := numberedPage(r, len(items))
+
+		return ok("module", "channels", "items", items[start:end], "pagination", paging)
+
+	case "people":
+		items := []*User{}
+
+		for _, user := range s.Workspace.Users {
+			if strings.Contains(user.Name, query) || strings.Contains(strings.ToLower(user.RealName), query) {
+				items = append(items, user)
+			}
+		}
+
+		start, end, paging := numberedPage(r, len(items))
+
+		return ok("module", "people", "items", items[start:end], "pagination", paging)
+	}
+
+	return ok("module", r.Args["module"], "items", []interface{}{})
+}
+
+func usersList(s *Server, r *Request) Response {
+	start, end, meta := cursorPage(r, len(s.Workspace.Users), 100)
+
+	return ok("members", s.Workspace.Users[start:end], "response_metadata", meta)
+}
+
+func usersInfo(s *Server, r *Request) Response {
+	user := s.Workspace.User(r.Args["user"])
+
+	if user == nil {
+		return fail("user_not_found")
+	}
+
+	return ok("user", user)
+}
+
+func usersLookupByEmail(s *Server, r *Request) Response {
+	for _, user := range s.Workspace.Users {
+		if strings.EqualFold(user.Profile.Email, r.Args["email"]) {
+			return ok("user", user)
+		}
+	}
+
+	return fail("users_not_found")
+}
+
+func usersIdentity(s *Server, r *Request) Response {
+	w := s.Workspace
+
+	return ok(
+		"user", map[string]interface{}{"id": r.User.ID, "name": r.User.RealName},
+		"team", map[string]interface{}{"id": w.TeamID},
+	)
+}
+
+func usersGetPresence(s *Server, r *Request) Response {
+	user := r.User
+
+	if id := r.Args["user"]; id != "" {
+		if user = s.Workspace.User(id); user == nil {
+			return fail("user_not_found")
+		}
+	}
+
+	return ok("presence", user.Presence)
+}
+
+func usersSetPresence(s *Server, r *Request) Response {
+	switch r.Args["presence"] {
+	case "auto":
+		r.User.Presence = "active"
+	case "away":
+		r.User.Presence = "away"
+	default:
+		return fail("invalid_presence")
+	}
+
+	return ok()
+}
+
+func usersProfileGet(s *Server, r *Request) Response {
+	user := r.User
+
+	if id := r.Args["user"]; id != "" {
+		if user = s.Workspace.User(id); user == nil {
+			return fail("user_not_found")
+		}
+	}
+
+	return ok("profile", user.Profile)
+}
+
+func usersProfileSet(s *Server, r *Request) Response {
+	if raw := r.Args["profile"]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &r.User.Profile); err != nil {
+			return fail("invalid_profile")
+		}
+	}
+
+	switch r.Args["name"] {
+	case "display_name":
+		r.User.Profile.DisplayName = r.Args["value"]
+	case "real_name":
+		r.User.Profile.RealName = r.Args["value"]
+	case "status_text":
+		r.User.Profile.StatusText = r.Args["value"]
+	case "status_emoji":
+		r.User.Profile.StatusEmoji = r.Args["value"]
+	case "email":
+		r.User.Profile.Email = r.Args["value"]
+	}
+
+	return ok("profile", r.User.Profile)
+}
+
+func usersSetStatus(s *Server, r *Request) Response {
+	r.User.Profile.StatusText = r.Args["status_text"]
+	r.User.Profile.StatusEmoji = r.Args["status_emoji"]
+
+	return ok("profile", r.User.Profile)
+}
+
+func migrationExchange(s *Server, r *Request) Response {
+	mapping := map[string]string{}
+
+	for _, id := range r.List("users") {
+		if s.Workspace.User(id) != nil {
+			mapping[id] = "W" + strings.TrimPrefix(id, "U")
+		}
+	}
+
+	return ok("team_id", s.Workspace.TeamID, "user_id_map", mapping)
+}
+
+func teamBillableInfo(s *Server, r *Request) Response {
+	info := map[string]interface{}{}
+
+	for _, user := range s.Workspace.Users {
+		if id := r.Args["user"]; id == "" || id == user.ID {
+			info[user.ID] = map[string]interface{}{"billing_active": !user.IsBot}
+		}
+	}
+
+	return ok("billable_info", info)
+}
+
+func teamChannelsInfo(s *Server, r *Request) Response {
+	return conversationsGenericInfo(s, &Request{Args: map[string]string{"channel_ids": r.Args["channels"]}, User: r.User})
+}
+
+func teamChannelsMembership(s *Server, r *Request) Response {
+	channel := s.Workspace.Channel(r.Args["channel"])
+
+	if channel == nil {
+		return fail("channel_not_found")
+	}
+
+	members := map[string]bool{}
+
+	for _, id := range r.List("users") {
+		members[id] = channel.IsMember(id)
+	}
+
+	return ok("members", members)
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
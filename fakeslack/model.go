@@ -0,0 +1,446 @@
+package fakeslack
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// User defines a member of the fake workspace.
+type User struct {
+	ID       string  `json:"id"`
+	TeamID   string  `json:"team_id"`
+	Name     string  `json:"name"`
+	RealName string  `json:"real_name"`
+	Deleted  bool    `json:"deleted"`
+	IsAdmin  bool    `json:"is_admin"`
+	IsBot    bool    `json:"is_bot"`
+	TZ       string  `json:"tz"`
+	Profile  Profile `json:"profile"`
+	Presence string  `json:"-"`
+}
+
+// Profile defines the profile of a user.
+type Profile struct {
+	DisplayName string `json:"display_name"`
+	RealName    string `json:"real_name"`
+	Email       string `json:"email"`
+	StatusText  string `json:"status_text"`
+	StatusEmoji string `json:"status_emoji"`
+}
+
+// Channel defines a conversation of the fake workspace.
+type Channel struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name,omitempty"`
+	IsChannel  bool     `json:"is_channel"`
+	IsGroup    bool     `json:"is_group"`
+	IsIM       bool     `json:"is_im"`
+	IsMpIM     bool     `json:"is_mpim"`
+	IsPrivate  bool     `json:"is_private"`
+	IsArchived bool     `json:"is_archived"`
+	User       string   `json:"user,omitempty"`
+	Creator    string   `json:"creator"`
+	Created    int64    `json:"created"`
+	Topic      Purpose  `json:"topic"`
+	Purpose    Purpose  `json:"purpose"`
+	NumMembers int      `json:"num_members"`
+	Members    []string `json:"-"`
+	LastRead   string   `json:"last_read,omitempty"`
+}
+
+// Purpose defines the topic or purpose of a channel.
+type Purpose struct {
+	Value   string `json:"value"`
+	Creator string `json:"creator"`
+	LastSet int64  `json:"last_set"`
+}
+
+// Message defines a message posted to a channel.
+type Message struct {
+	Type        string                   `json:"type"`
+	Subtype     string                   `json:"subtype,omitempty"`
+	Channel     string                   `json:"-"`
+	User        string                   `json:"user,omitempty"`
+	Username    string                   `json:"username,omitempty"`
+	Text        string                   `json:"text"`
+	Ts          string                   `json:"ts"`
+	ThreadTs    string                   `json:"thread_ts,omitempty"`
+	ReplyCount  int                      `json:"reply_count,omitempty"`
+	Attachments []map[string]interface{} `json:"attachments,omitempty"`
+	Blocks      []interface{}            `json:"blocks,omitempty"`
+	Reactions   []*Reaction              `json:"reactions,omitempty"`
+	Edited      *Edited                  `json:"edited,omitempty"`
+	PostAt      int64                    `json:"-"`
+	ScheduleID  string                   `json:"-"`
+}
+
+// Edited defines who and when edited a message.
+type Edited struct {
+	User string `json:"user"`
+	Ts   string `json:"ts"`
+}
+
+// Reaction defines the users who reacted to an item with an emoji.
+type Reaction struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Users []string `json:"users"`
+}
+
+// File defines a file uploaded to the fake workspace.
+type File struct {
+	ID         string      `json:"id"`
+	Created    int64       `json:"created"`
+	Name       string      `json:"name"`
+	Title      string      `json:"title"`
+	Filetype   string      `json:"filetype"`
+	Size       int         `json:"size"`
+	User       string      `json:"user"`
+	Channels   []string    `json:"channels"`
+	IsPublic   bool        `json:"is_public"`
+	PublicURL  bool        `json:"public_url_shared"`
+	Permalink  string      `json:"permalink"`
+	Comments   []*Comment  `json:"-"`
+	Reactions  []*Reaction `json:"reactions,omitempty"`
+	Content    []byte      `json:"-"`
+	NumComment int         `json:"comments_count"`
+}
+
+// Comment defines a comment on a file.
+type Comment struct {
+	ID      string `json:"id"`
+	Created int64  `json:"created"`
+	User    string `json:"user"`
+	Comment string `json:"comment"`
+}
+
+// Item references a message or a file, which can be pinned or starred.
+type Item struct {
+	Type    string   `json:"type"`
+	Channel string   `json:"channel,omitempty"`
+	Message *Message `json:"message,omitempty"`
+	File    *File    `json:"file,omitempty"`
+	Created int64    `json:"created"`
+}
+
+// Workspace is the in-memory model of a Slack team.
+type Workspace struct {
+	TeamID   string
+	TeamName string
+	Domain   string
+	Users    []*User
+	Channels []*Channel
+	Messages []*Message
+	Files    []*File
+	Pins     []*Item
+	Stars    []*Item
+	Emoji    map[string]string
+	Snooze   map[string]int64
+	clock    int64
+	serial   int
+}
+
+// NewWorkspace returns a workspace with two users, a bot, two public channels,
+// a private channel and a few messages.
+func NewWorkspace() *Workspace {
+	w := &Workspace{
+		TeamID:   "T00000001",
+		TeamName: "Acme",
+		Domain:   "acme",
+		Emoji:    map[string]string{"shipit": "https://emoji.example.com/shipit.png"},
+		Snooze:   map[string]int64{},
+		clock:    1700000000,
+	}
+
+	w.Users = []*User{
+		{ID: "U00000001", Name: "alice", RealName: "Alice Doe", IsAdmin: true, TZ: "America/New_York", Profile: Profile{DisplayName: "alice", RealName: "Alice Doe", Email: "alice@example.com"}},
+		{ID: "U00000002", Name: "bob", RealName: "Bob Roe", TZ: "Europe/London", Profile: Profile{DisplayName: "bobby", RealName: "Bob Roe", Email: "bob@example.com"}},
+		{ID: "U00000003", Name: "deploybot", RealName: "Deploy Bot", IsBot: true, TZ: "UTC", Profile: Profile{DisplayName: "deploybot", RealName: "Deploy Bot"}},
+	}
+
+	for _, user := range w.Users {
+		user.TeamID = w.TeamID
+		user.Presence = "active"
+	}
+
+	w.Channels = []*Channel{
+		{ID: "C00000001", Name: "general", IsChannel: true, Creator: "U00000001", Members: []string{"U00000001", "U00000002", "U00000003"}},
+		{ID: "C00000002", Name: "random", IsChannel: true, Creator: "U00000001", Members: []string{"U00000001", "U00000002"}},
+		{ID: "G00000003", Name: "secret", IsGroup: true, IsPrivate: true, Creator: "U00000001", Members: []string{"U00000001"}},
+	}
+
+	for _, channel := range w.Channels {
+		channel.Created = w.clock
+		channel.NumMembers = len(channel.Members)
+	}
+
+	w.Channels[0].Topic = Purpose{Value: "Company wide announcements", Creator: "U00000001", LastSet: w.clock}
+
+	w.Post(&Message{Channel: "C00000001", User: "U00000001", Text: "Welcome to Acme"})
+	w.Post(&Message{Channel: "C00000001", User: "U00000002", Text: "Hello everyone"})
+	w.Post(&Message{Channel: "C00000002", User: "U00000002", Text: "Lunch?"})
+
+	return w
+}
+
+// NextID returns a new identifier with the given prefix, like C00000004.
+func (w *Workspace) NextID(prefix string) string {
+	w.serial++
+	return fmt.Sprintf("%s1%07d", prefix, w.serial)
+}
+
+// NextTs returns a new message timestamp, which is unique and always grows.
+func (w *Workspace) NextTs() string {
+	w.clock++
+	return fmt.Sprintf("%d.000100", w.clock)
+}
+
+// Now returns the current time of the fake workspace, in seconds.
+func (w *Workspace) Now() int64 {
+	return w.clock
+}
+
+// Post adds a message to a channel and returns it.
+func (w *Workspace) Post(msg *Message) *Message {
+	msg.Type = "message"
+	msg.Ts = w.NextTs()
+
+	w.Messages = append(w.Messages, msg)
+
+	if msg.ThreadTs != "" {
+		if parent := w.Message(msg.Channel, msg.ThreadTs); parent != nil {
+			parent.ReplyCount++
+		}
+	}
+
+	return msg
+}
+
+// User returns a user by ID, or nil.
+func (w *Workspace) User(id string) *User {
+	for _, user := range w.Users {
+		if user.ID == id {
+			return user
+		}
+	}
+
+	return nil
+}
+
+// UserByName returns a user by username or email, or nil.
+func (w *Workspace) UserByName(name string) *User {
+	for _, user := range w.Users {
+		if user.Name == name || strings.EqualFold(user.Profile.Email, name) {
+			return user
+		}
+	}
+
+	return nil
+}
+
+// Channel returns a channel by ID, or nil.
+func (w *Workspace) Channel(id string) *Channel {
+	for _, channel := range w.Channels {
+		if channel.ID == id {
+			return channel
+		}
+	}
+
+	return nil
+}
+
+// ChannelByName returns a channel by name, or nil.
+func (w *Workspace) ChannelByName(name string) *Channel {
+	for _, channel := range w.Channels {
+		if channel.Name == name {
+			return channel
+		}
+	}
+
+	return nil
+}
+
+// Message returns a message by channel and timestamp, or nil.
+func (w *Workspace) Message(channel string, ts string) *Message {
+	for _, msg := range w.Messages {
+		if msg.Channel == channel && msg.Ts == ts {
+			return msg
+		}
+	}
+
+	return nil
+}
+
+// Schedule adds a message that is posted later, at the time in PostAt, and
+// returns it with its scheduled_message_id.
+func (w *Workspace) Schedule(msg *Message) *Message {
+	msg.Type = "message"
+	msg.ScheduleID = w.NextID("Q")
+
+	w.Messages = append(w.Messages, msg)
+
+	return msg
+}
+
+// Scheduled returns the messages scheduled for later in a channel, or in
+// every channel if it is empty, sooner first.
+func (w *Workspace) Scheduled(channel string) []*Message {
+	var list []*Message
+
+	for _, msg := range w.Messages {
+		if msg.PostAt != 0 && (channel == "" || msg.Channel == channel) {
+			list = append(list, msg)
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].PostAt < list[j].PostAt
+	})
+
+	return list
+}
+
+// Unschedule removes a message scheduled for later.
+func (w *Workspace) Unschedule(channel string, id string) bool {
+	for i, msg := range w.Messages {
+		if msg.PostAt != 0 && msg.Channel == channel && msg.ScheduleID == id {
+			w.Messages = append(w.Messages[:i], w.Messages[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// DeleteMessage removes a message from a channel.
+func (w *Workspace) DeleteMessage(channel string, ts string) bool {
+	for i, msg := range w.Messages {
+		if msg.Channel == channel && msg.Ts == ts {
+			w.Messages = append(w.Messages[:i], w.Messages[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// History returns the messages of a channel, newest first, excluding the
+// replies in threads and the messages scheduled for later.
+func (w *Workspace) History(channel string) []*Message {
+	var list []*Message
+
+	for _, msg := range w.Messages {
+		if msg.Channel == channel && (msg.ThreadTs == "" || msg.ThreadTs == msg.Ts) && msg.PostAt == 0 {
+			list = append(list, msg)
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Ts > list[j].Ts
+	})
+
+	return list
+}
+
+// Replies returns a thread, starting with the parent message.
+func (w *Workspace) Replies(channel string, ts string) []*Message {
+	var list []*Message
+
+	for _, msg := range w.Messages {
+		if msg.Channel == channel && (msg.Ts == ts || msg.ThreadTs == ts) {
+			list = append(list, msg)
+		}
+	}
+
+	return list
+}
+
+// File returns a file by ID, or nil.
+func (w *Workspace) File(id string) *File {
+	for _, file := range w.Files {
+		if file.ID == id {
+			return file
+		}
+	}
+
+	return nil
+}
+
+// IsMember returns true if the user belongs to the channel.
+func (c *Channel) IsMember(user string) bool {
+	for _, member := range c.Members {
+		if member == user {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddMember adds a user to the channel.
+func (c *Channel) AddMember(user string) {
+	if !c.IsMember(user) {
+		c.Members = append(c.Members, user)
+		c.NumMembers = len(c.Members)
+	}
+}
+
+// RemoveMember removes a user from the channel.
+func (c *Channel) RemoveMember(user string) {
+	for i, member := range c.Members {
+		if member == user {
+			c.Members = append(c.Members[:i], c.Members[i+1:]...)
+			c.NumMembers = len(c.Members)
+			return
+		}
+	}
+}
+
+// addReaction adds a user to the reaction with the given emoji.
+func addReaction(reactions []*Reaction, name string, user string) ([]*Reaction, bool) {
+	for _, reaction := range reactions {
+		if reaction.Name != name {
+			continue
+		}
+
+		for _, existing := range reaction.Users {
+			if existing == user {
+				return reactions, false
+			}
+		}
+
+		reaction.Users = append(reaction.Users, user)
+		reaction.Count = len(reaction.Users)
+
+		return reactions, true
+	}
+
+	return append(reactions, &Reaction{Name: name, Count: 1, Users: []string{user}}), true
+}
+
+// removeReaction removes a user from the reaction with the given emoji.
+func removeReaction(reactions []*Reaction, name string, user string) ([]*Reaction, bool) {
+	for i, reaction := range reactions {
+		if reaction.Name != name {
+			continue
+		}
+
+		for j, existing := range reaction.Users {
+			if existing != user {
+				continue
+			}
+
+			reaction.Users = append(reaction.Users[:j], reaction.Users[j+1:]...)
+			reaction.Count = len(reaction.Users)
+
+			if reaction.Count == 0 {
+				reactions = append(reactions[:i], reactions[i+1:]...)
+			}
+
+			return reactions, true
+		}
+	}
+
+	return reactions, false
+}
@@ -0,0 +1,173 @@
+package fakeslack
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// websocketGUID is appended to the key of the handshake, as described in
+// RFC 6455, to compute the Sec-WebSocket-Accept header.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes used by the real-time messaging connection.
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+)
+
+// serveWebSocket answers the real-time messaging connection opened with the
+// URL returned by rtm.connect. It sends a hello event, a message from the
+// second user of the workspace and a message with the text "disconnect",
+// which makes slackcli rtm.events stop, then waits for the client to close
+// the connection.
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "expected a WebSocket handshake", http.StatusBadRequest)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+
+	if !ok {
+		http.Error(w, "WebSocket is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	conn, rw, err := hijacker.Hijack()
+
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	sum := sha1.Sum([]byte(key + websocketGUID))
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\n")
+	rw.WriteString("Connection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n")
+
+	s.mu.Lock()
+	workspace := s.Workspace
+	events := []Response{
+		{"type": "hello"},
+		{"type": "message", "channel": workspace.Channels[0].ID, "user": workspace.Users[1].ID, "text": "Hello from the fake server", "ts": workspace.NextTs()},
+		{"type": "message", "channel": workspace.Channels[0].ID, "user": workspace.Users[1].ID, "text": "disconnect", "ts": workspace.NextTs()},
+	}
+	s.mu.Unlock()
+
+	for _, event := range events {
+		data, _ := json.Marshal(event)
+
+		if err := writeFrame(rw.Writer, opText, data); err != nil {
+			return
+		}
+	}
+
+	for {
+		opcode, payload, err := readFrame(rw.Reader)
+
+		if err != nil {
+			return
+		}
+
+		switch opcode {
+		case opClose:
+			writeFrame(rw.Writer, opClose, payload)
+			return
+		case opPing:
+			if err := writeFrame(rw.Writer, opPong, payload); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeFrame sends an unmasked frame, like every frame sent by a server.
+func writeFrame(w *bufio.Writer, opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+
+	switch size := len(payload); {
+	case size < 126:
+		header = append(header, byte(size))
+	case size <= 0xFFFF:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(size))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(size))
+	}
+
+	w.Write(header)
+	w.Write(payload)
+
+	return w.Flush()
+}
+
+// readFrame receives a frame from the client, which is always masked.
+func readFrame(r *bufio.Reader) (byte, []byte, error) {
+	var header [2]byte
+
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	if header[1]&0x80 == 0 {
+		return 0, nil, errors.New("unmasked frame from the client")
+	}
+
+	size := uint64(header[1] & 0x7F)
+
+	switch size {
+	case 126:
+		var ext [2]byte
+
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if size > 1<<20 {
+		return 0, nil, errors.New("frame too large")
+	}
+
+	var mask [4]byte
+
+	if _, err := io.ReadFull(r, mask[:]); err != nil {
+		return 0, nil, err
+	}
+
+	payload := make([]byte, size)
+
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return header[0] & 0x0F, payload, nil
+}
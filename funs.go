@@ -2,23 +2,46 @@ package main
 
 import (
 	"encoding/json"
-	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cixtor/slackapi"
 )
 
-// CallHelp prints the description and usage options.
+// CallHelp prints the description and usage options, the commands in a
+// namespace, or the help page of a command.
 func (cli *CLI) CallHelp() int {
-	flag.Usage()
-	return 2
+	name := cli.String("command")
+
+	if name == "" {
+		cli.flags.Usage()
+		return ExitSuccess
+	}
+
+	if commands := cli.Namespace(name); len(commands) > 0 {
+		fmt.Fprintf(cli.stdout, "Commands in %s:\n", name)
+		cli.PrintCommands(commands)
+		return ExitSuccess
+	}
+
+	command, err := cli.LookupCommand(name)
+
+	if err != nil {
+		return cli.PrintError(UsageError("help; %s", err.(*Error).Code))
+	}
+
+	cli.printCommandHelp(command)
+
+	return ExitSuccess
 }
 
 // CallAPITest sends a http request with the api.test action.
 func (cli *CLI) CallAPITest() int {
-	return cli.PrintJSON(cli.api.APITest(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.APITest(cli.String("error")))
 }
 
 // CallAPIGetFlannelHTTPURL sends a http request with the api.getFlannelHttpUrl action.
@@ -33,11 +56,13 @@ func (cli *CLI) CallAppsConnectionsOpen() int {
 
 // CallAppsEventAuthorizationsList sends a http request with the apps.event.authorizations.list action.
 func (cli *CLI) CallAppsEventAuthorizationsList() int {
-	return cli.PrintJSON(cli.api.AppsEventAuthorizationsList(slackapi.AppsEventAuthorizationsListInput{
-		EventContext: flag.Arg(1),
-		Cursor:       flag.Arg(2),
-		Limit:        cli.Number(3, 100),
-	}))
+	return cli.PrintCursorPages(func(cursor string) interface{} {
+		return cli.api.AppsEventAuthorizationsList(slackapi.AppsEventAuthorizationsListInput{
+			EventContext: cli.String("event_context"),
+			Cursor:       cursor,
+			Limit:        cli.Number("limit"),
+		})
+	})
 }
 
 // CallAppsList sends a http request with the apps.list action.
@@ -47,32 +72,110 @@ func (cli *CLI) CallAppsList() int {
 
 // CallAppsManifestCreate sends a http request with the apps.manifest.create action.
 func (cli *CLI) CallAppsManifestCreate() int {
-	return cli.PrintJSON(cli.api.AppsManifestCreate(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.AppsManifestCreate(cli.String("manifest")))
 }
 
 // CallAppsManifestDelete sends a http request with the apps.manifest.delete action.
 func (cli *CLI) CallAppsManifestDelete() int {
-	return cli.PrintJSON(cli.api.AppsManifestDelete(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.AppsManifestDelete(cli.String("app_id")))
 }
 
 // CallAppsManifestExport sends a http request with the apps.manifest.export action.
 func (cli *CLI) CallAppsManifestExport() int {
-	return cli.PrintJSON(cli.api.AppsManifestExport(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.AppsManifestExport(cli.String("app_id")))
 }
 
 // CallAppsManifestUpdate sends a http request with the apps.manifest.update action.
 func (cli *CLI) CallAppsManifestUpdate() int {
-	return cli.PrintJSON(cli.api.AppsManifestUpdate(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.AppsManifestUpdate(cli.String("app_id"), cli.String("manifest")))
 }
 
 // CallAppsManifestValidate sends a http request with the apps.manifest.validate action.
 func (cli *CLI) CallAppsManifestValidate() int {
-	return cli.PrintJSON(cli.api.AppsManifestValidate(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.AppsManifestValidate(cli.String("manifest"), cli.String("app_id")))
+}
+
+// CallAuthLogin verifies a token and saves it in the credential store. If the
+// token is not passed, or read from stdin with "-", it is requested in the
+// terminal to keep it out of the shell history.
+func (cli *CLI) CallAuthLogin() int {
+	var err error
+
+	creds := Credentials{
+		Token:  cli.String("token"),
+		Cookie: cli.String("cookie"),
+	}
+
+	if creds.Token == "" {
+		if creds.Token, err = readSecret("token", "Token: ", "use - to read it from stdin"); err != nil {
+			return cli.PrintError(fmt.Errorf("auth.login; %s", err))
+		}
+
+		if creds.Cookie, err = readSecret("cookie", "Cookie (optional): ", "use --cookie=- to read it from stdin"); err != nil {
+			return cli.PrintError(fmt.Errorf("auth.login; %s", err))
+		}
+	}
+
+	cli.api.SetToken(creds.Token)
+	cli.api.SetCookie(creds.Cookie)
+
+	res, err := cli.api.AuthTest()
+
+	if err != nil {
+		return cli.PrintError(fmt.Errorf("auth.login; %s", err))
+	}
+
+	if !ResponseOf(res).Ok {
+		return cli.PrintJSON(res)
+	}
+
+	if err := cli.store.Store(cli.profileKey(), creds); err != nil {
+		return cli.PrintError(fmt.Errorf("auth.login; %s", err))
+	}
+
+	if err := cli.forgetPlaintextCredentials(); err != nil {
+		return cli.PrintError(fmt.Errorf("auth.login; %s", err))
+	}
+
+	return cli.PrintJSON(res)
+}
+
+// CallAuthLogout wipes the credentials of the active profile from the
+// credential store and the configuration file.
+func (cli *CLI) CallAuthLogout() int {
+	if err := cli.store.Erase(cli.profileKey()); err != nil {
+		return cli.PrintError(fmt.Errorf("auth.logout; %s", err))
+	}
+
+	if err := cli.forgetPlaintextCredentials(); err != nil {
+		return cli.PrintError(fmt.Errorf("auth.logout; %s", err))
+	}
+
+	return cli.PrintJSON(slackapi.Response{Ok: true})
+}
+
+// forgetPlaintextCredentials removes the token and cookie of the active
+// profile from the configuration file, if any.
+func (cli *CLI) forgetPlaintextCredentials() error {
+	if !cli.config.HasProfile(cli.profile.Name) {
+		return nil
+	}
+
+	section := cli.config.Section("profile " + cli.profile.Name)
+
+	if section.Get("token") == "" && section.Get("cookie") == "" {
+		return nil
+	}
+
+	section.Unset("token")
+	section.Unset("cookie")
+
+	return cli.config.Save()
 }
 
 // CallAuthRevoke sends a http request with the auth.revoke action.
 func (cli *CLI) CallAuthRevoke() int {
-	if flag.Arg(1) == "test" {
+	if cli.String("test") == "test" {
 		return cli.PrintJSON(cli.api.AuthRevoke(true))
 	} else {
 		return cli.PrintJSON(cli.api.AuthRevoke(false))
@@ -81,74 +184,221 @@ func (cli *CLI) CallAuthRevoke() int {
 
 // CallAuthTeamsList sends a http request with the auth.teams.list action.
 func (cli *CLI) CallAuthTeamsList() int {
-	return cli.PrintJSON(cli.api.AuthTeamsList(slackapi.AuthTeamsListInput{
-		Cursor:      flag.Arg(1),
-		IncludeIcon: flag.Arg(2) == "true",
-		Limit:       cli.Number(3, 100),
-	}))
+	return cli.PrintCursorPages(func(cursor string) interface{} {
+		return cli.api.AuthTeamsList(slackapi.AuthTeamsListInput{
+			Cursor:      cursor,
+			IncludeIcon: cli.Bool("include_icon"),
+			Limit:       cli.Number("limit"),
+		})
+	})
 }
 
 // CallAuthTest sends a http request with the auth.test action.
 func (cli *CLI) CallAuthTest() int {
 	res, err := cli.api.AuthTest()
 	if err != nil {
-		fmt.Println(err)
-		return 1
+		return cli.PrintError(fmt.Errorf("auth.test; %s", err))
 	}
 	return cli.PrintJSON(res)
 }
 
 // CallBotsInfo sends a http request with the bots.info action.
 func (cli *CLI) CallBotsInfo() int {
-	return cli.PrintJSON(cli.api.BotsInfo(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.BotsInfo(cli.String("bot")))
+}
+
+// CallCacheClear deletes the local copy of the channels and users.
+func (cli *CLI) CallCacheClear() int {
+	if err := os.Remove(cli.CachePath()); err != nil && !os.IsNotExist(err) {
+		return cli.PrintError(fmt.Errorf("cache.clear; %s", err))
+	}
+
+	cli.cache = nil
+
+	return cli.PrintJSON(slackapi.Response{Ok: true})
+}
+
+// CallCacheRefresh downloads the channels and users used to resolve names.
+func (cli *CLI) CallCacheRefresh() int {
+	cache, err := cli.refreshCache()
+
+	if err != nil {
+		return cli.PrintError(fmt.Errorf("cache.refresh; %s", err))
+	}
+
+	return cli.PrintJSON(struct {
+		slackapi.Response
+		Path     string `json:"path"`
+		Channels int    `json:"channels"`
+		Users    int    `json:"users"`
+	}{
+		Response: slackapi.Response{Ok: true},
+		Path:     cli.CachePath(),
+		Channels: len(cache.Channels),
+		Users:    len(cache.Users),
+	})
+}
+
+// CallCall sends a http request with any action of the web API service.
+func (cli *CLI) CallCall() int {
+	method := cli.String("method")
+
+	if method == "" {
+		return cli.PrintError(UsageError("call; missing method name"))
+	}
+
+	raw, err := ParseRawArgs(cli.Args("args"))
+
+	if err != nil {
+		return cli.PrintError(UsageError("call; %s", err))
+	}
+
+	httpMethod := http.MethodPost
+
+	if cli.Bool("get") {
+		httpMethod = http.MethodGet
+	}
+
+	res, err := cli.Invoke(httpMethod, method, raw)
+
+	if err != nil {
+		return cli.PrintError(fmt.Errorf("call; %s", err))
+	}
+
+	return cli.PrintJSON(res)
 }
 
 // CallChatDelete sends a http request with the chat.delete action.
 func (cli *CLI) CallChatDelete() int {
 	return cli.PrintJSON(cli.api.ChatDelete(slackapi.MessageArgs{
-		Channel: flag.Arg(1),
-		Ts:      flag.Arg(2),
+		Channel: cli.String("channel"),
+		Ts:      cli.String("time"),
 	}))
 }
 
 // CallChatDeleteAttachment sends a http request with the chat.delete action.
 func (cli *CLI) CallChatDeleteAttachment() int {
 	return cli.PrintJSON(cli.api.ChatDeleteAttachment(slackapi.ChatDeleteAttachmentInput{
-		Channel:    flag.Arg(1),
-		Ts:         flag.Arg(2),
-		Attachment: cli.Number(3, 1),
+		Channel:    cli.String("channel"),
+		Ts:         cli.String("time"),
+		Attachment: cli.Number("attachment"),
+	}))
+}
+
+// CallChatDeleteScheduledMessage sends a http request with the chat.deleteScheduledMessage action.
+func (cli *CLI) CallChatDeleteScheduledMessage() int {
+	return cli.PrintJSON(cli.InvokeResult("chat.deleteScheduledMessage", map[string]string{
+		"channel":              cli.String("channel"),
+		"scheduled_message_id": cli.String("scheduled_message_id"),
+	}))
+}
+
+// CallChatGetPermalink sends a http request with the chat.getPermalink action.
+func (cli *CLI) CallChatGetPermalink() int {
+	return cli.PrintJSON(cli.InvokeResult("chat.getPermalink", map[string]string{
+		"channel":    cli.String("channel"),
+		"message_ts": cli.String("time"),
 	}))
 }
 
+// CallChatDM sends a http request with the chat.postMessage action to the
+// direct message with a user, which is opened if necessary.
+func (cli *CLI) CallChatDM() int {
+	channel, err := cli.openDirectMessage(cli.String("user"))
+
+	if err != nil {
+		return cli.PrintError(fmt.Errorf("chat.dm; %s", err))
+	}
+
+	return cli.sendMessage("chat.postMessage", slackapi.MessageArgs{
+		Channel: channel,
+		Text:    cli.String("text"),
+	})
+}
+
 // CallChatMeMessage sends a http request with the chat.meMessage action.
 func (cli *CLI) CallChatMeMessage() int {
-	return cli.PrintJSON(cli.api.ChatMeMessage(slackapi.MessageArgs{
-		Channel: flag.Arg(1),
-		Text:    flag.Arg(2),
-	}))
+	return cli.sendMessage("chat.meMessage", slackapi.MessageArgs{
+		Channel: cli.String("channel"),
+		Text:    cli.String("text"),
+	})
+}
+
+// CallChatMPDM sends a http request with the chat.postMessage action to the
+// multi-person direct message with a group of users, which is opened if
+// necessary.
+func (cli *CLI) CallChatMPDM() int {
+	users := cli.List("users")
+
+	if len(users) < 2 || len(users) > 8 {
+		return cli.PrintError(UsageError("chat.mpdm; users expects between 2 and 8 users, use chat.dm to message one user"))
+	}
+
+	channel, err := cli.openDirectMessage(strings.Join(users, ","))
+
+	if err != nil {
+		return cli.PrintError(fmt.Errorf("chat.mpdm; %s", err))
+	}
+
+	return cli.sendMessage("chat.postMessage", slackapi.MessageArgs{
+		Channel: channel,
+		Text:    cli.String("text"),
+	})
 }
 
 // CallChatPostAttachment sends a http request with the chat.postAttachment action.
 func (cli *CLI) CallChatPostAttachment() int {
 	var data slackapi.Attachment
 
-	if err := json.Unmarshal([]byte(flag.Arg(2)), &data); err != nil {
-		fmt.Printf("{\"ok\":false, \"error\":\"json.decode; %s\"}\n", err.Error())
-		return 1
+	if err := json.Unmarshal([]byte(cli.String("json")), &data); err != nil {
+		return cli.PrintError(UsageError("json.decode; %s", err))
 	}
 
 	return cli.PrintJSON(cli.api.ChatPostMessage(slackapi.MessageArgs{
-		Channel:     flag.Arg(1),
+		Channel:     cli.String("channel"),
 		Attachments: []slackapi.Attachment{data},
 	}))
 }
 
+// CallChatPostEphemeral sends a http request with the chat.postEphemeral action.
+func (cli *CLI) CallChatPostEphemeral() int {
+	raw, _, err := cli.messageRequest(slackapi.MessageArgs{
+		Channel: cli.String("channel"),
+		Text:    cli.String("text"),
+	})
+
+	if err != nil {
+		return cli.PrintError(UsageError("chat.postEphemeral; %s", err))
+	}
+
+	raw.Values["user"] = cli.String("user")
+
+	return cli.invokeMessage("chat.postEphemeral", raw)
+}
+
 // CallChatPostMessage sends a http request with the chat.postMessage action.
 func (cli *CLI) CallChatPostMessage() int {
-	return cli.PrintJSON(cli.api.ChatPostMessage(slackapi.MessageArgs{
-		Channel: flag.Arg(1),
-		Text:    flag.Arg(2),
-	}))
+	return cli.sendMessage("chat.postMessage", slackapi.MessageArgs{
+		Channel: cli.String("channel"),
+		Text:    cli.String("text"),
+	})
+}
+
+// CallChatReply sends a http request with the chat.postMessage action to
+// reply in the thread of the message with the given URL.
+func (cli *CLI) CallChatReply() int {
+	link, err := ParsePermalink(cli.String("permalink"))
+
+	if err != nil {
+		return cli.PrintError(UsageError("chat.reply; %s", err))
+	}
+
+	cli.params["thread-ts"] = link.Thread()
+
+	return cli.sendMessage("chat.postMessage", slackapi.MessageArgs{
+		Channel: link.Channel,
+		Text:    cli.String("text"),
+	})
 }
 
 // CallChatRobotMessage sends a http request with the chat.robotMessage action.
@@ -156,9 +406,17 @@ func (cli *CLI) CallChatRobotMessage() int {
 	robotName := os.Getenv("SLACK_ROBOT_NAME")
 	robotImage := os.Getenv("SLACK_ROBOT_IMAGE")
 
+	if robotName == "" {
+		robotName = cli.profile.RobotName
+	}
+
+	if robotImage == "" {
+		robotImage = cli.profile.RobotImage
+	}
+
 	data := slackapi.MessageArgs{
-		Channel: flag.Arg(1),
-		Text:    flag.Arg(2),
+		Channel: cli.String("channel"),
+		Text:    cli.String("text"),
 		AsUser:  false,
 	}
 
@@ -178,16 +436,53 @@ func (cli *CLI) CallChatRobotMessage() int {
 		data.IconURL = robotImage
 	}
 
-	return cli.PrintJSON(cli.api.ChatPostMessage(data))
+	return cli.sendMessage("chat.postMessage", data)
+}
+
+// CallChatScheduleMessage sends a http request with the chat.scheduleMessage action.
+func (cli *CLI) CallChatScheduleMessage() int {
+	postAt, err := ParsePostTime(cli.String("post_at"), time.Now(), cli.userLocation)
+
+	if err != nil {
+		e := ClassifyError(err)
+		e.Code = "chat.scheduleMessage; " + e.Code
+		return cli.PrintError(e)
+	}
+
+	raw, _, err := cli.messageRequest(slackapi.MessageArgs{
+		Channel: cli.String("channel"),
+		Text:    cli.String("text"),
+	})
+
+	if err != nil {
+		return cli.PrintError(UsageError("chat.scheduleMessage; %s", err))
+	}
+
+	raw.Values["post_at"] = strconv.FormatInt(postAt.Unix(), 10)
+
+	return cli.invokeMessage("chat.scheduleMessage", raw)
+}
+
+// CallChatScheduledMessagesList sends a http request with the chat.scheduledMessages.list action.
+func (cli *CLI) CallChatScheduledMessagesList() int {
+	return cli.PrintCursorPages(func(cursor string) interface{} {
+		return cli.InvokeResult("chat.scheduledMessages.list", map[string]string{
+			"channel": cli.String("channel"),
+			"oldest":  cli.String("oldest"),
+			"latest":  cli.String("latest"),
+			"limit":   cli.String("limit"),
+			"cursor":  cursor,
+		})
+	})
 }
 
 // CallChatUpdate sends a http request with the chat.update action.
 func (cli *CLI) CallChatUpdate() int {
-	return cli.PrintJSON(cli.api.ChatUpdate(slackapi.MessageArgs{
-		Channel: flag.Arg(1),
-		Ts:      flag.Arg(2),
-		Text:    flag.Arg(3),
-	}))
+	return cli.sendMessage("chat.update", slackapi.MessageArgs{
+		Channel: cli.String("channel"),
+		Ts:      cli.String("time"),
+		Text:    cli.String("text"),
+	})
 }
 
 // CallClientCounts sends a http request with the client.counts action.
@@ -201,85 +496,85 @@ func (cli *CLI) CallClientCounts() int {
 // CallClientShouldReload sends a http request with the client.shouldReload action.
 func (cli *CLI) CallClientShouldReload() int {
 	return cli.PrintJSON(cli.api.ClientShouldReload(slackapi.ClientShouldReloadInput{
-		TeamIDs:         flag.Arg(1),
-		VersionTs:       cli.Number(2, 1),
-		BuildVersionTs:  cli.Number(3, 1),
-		ConfigVersionTs: cli.Number(4, 1),
+		TeamIDs:         cli.String("team_ids"),
+		VersionTs:       cli.Number("version_ts"),
+		BuildVersionTs:  cli.Number("build_version_ts"),
+		ConfigVersionTs: cli.Number("config_version_ts"),
 	}))
 }
 
 // CallConversationsAcceptSharedInvite sends a http request with the conversations.acceptSharedInvite action.
 func (cli *CLI) CallConversationsAcceptSharedInvite() int {
 	return cli.PrintJSON(cli.api.ConversationsAcceptSharedInvite(slackapi.ConversationsAcceptSharedInviteInput{
-		ChannelName:       flag.Arg(1),
-		ChannelID:         flag.Arg(2),
-		FreeTrialAccepted: flag.Arg(3) == "true",
-		InviteID:          flag.Arg(4),
-		IsPrivate:         flag.Arg(5) == "true",
-		TeamID:            flag.Arg(6),
+		ChannelName:       cli.String("channel_name"),
+		ChannelID:         cli.String("channel_id"),
+		FreeTrialAccepted: cli.Bool("free_trial_accepted"),
+		InviteID:          cli.String("invite_id"),
+		IsPrivate:         cli.Bool("is_private"),
+		TeamID:            cli.String("team_id"),
 	}))
 }
 
 // CallConversationsApproveSharedInvite sends a http request with the conversations.acceptSharedInvite action.
 func (cli *CLI) CallConversationsApproveSharedInvite() int {
-	return cli.PrintJSON(cli.api.ConversationsApproveSharedInvite(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.ConversationsApproveSharedInvite(cli.String("invite_id"), cli.String("target_team")))
 }
 
 // CallConversationsArchive sends a http request with the conversations.archive action.
 func (cli *CLI) CallConversationsArchive() int {
-	return cli.PrintJSON(cli.api.ConversationsArchive(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.ConversationsArchive(cli.String("room")))
 }
 
 // CallConversationsClose sends a http request with the conversations.close action.
 func (cli *CLI) CallConversationsClose() int {
-	return cli.PrintJSON(cli.api.ConversationsClose(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.ConversationsClose(cli.String("room")))
 }
 
 // CallConversationsCreate sends a http request with the conversations.create action.
 func (cli *CLI) CallConversationsCreate() int {
 	return cli.PrintJSON(cli.api.ConversationsCreate(slackapi.ConversationsCreateInput{
-		Name:      flag.Arg(1),
-		IsPrivate: flag.Arg(2) == "true",
-		TeamID:    flag.Arg(3),
+		Name:      cli.String("name"),
+		IsPrivate: cli.Bool("is_private"),
+		TeamID:    cli.String("team_id"),
 	}))
 }
 
 // CallConversationsDeclineSharedInvite sends a http request with the conversations.declineSharedInvite action.
 func (cli *CLI) CallConversationsDeclineSharedInvite() int {
 	return cli.PrintJSON(cli.api.ConversationsDeclineSharedInvite(slackapi.ConversationsDeclineSharedInviteInput{
-		InviteID:   flag.Arg(1),
-		TargetTeam: flag.Arg(2),
+		InviteID:   cli.String("invite_id"),
+		TargetTeam: cli.String("target_team"),
 	}))
 }
 
 // CallConversationsDelete sends a http request with the conversations.delete action.
 func (cli *CLI) CallConversationsDelete() int {
-	return cli.PrintJSON(cli.api.ConversationsDelete(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.ConversationsDelete(cli.String("channel")))
 }
 
 // CallConversationsGenericInfo sends a http request with the conversations.genericInfo action.
 func (cli *CLI) CallConversationsGenericInfo() int {
-	return cli.PrintJSON(cli.api.ConversationsGenericInfo(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.ConversationsGenericInfo(cli.String("channels")))
 }
 
 // CallConversationsHistory sends a http request with the conversations.history action.
 func (cli *CLI) CallConversationsHistory() int {
 	return cli.PrintJSON(cli.api.ConversationsHistory(
 		slackapi.ConversationsHistoryInput{
-			Channel: flag.Arg(1),
-			Latest:  flag.Arg(2),
+			Channel: cli.String("room"),
+			Latest:  cli.String("time"),
 		},
 	))
 }
 
 // CallConversationsID sends a http request with the conversations.id action.
 func (cli *CLI) CallConversationsID() int {
-	channel := flag.Arg(1)
+	channel := cli.String("room")
 	result := cli.api.SearchModules(slackapi.SearchModulesInput{
 		Module:            "channels",
 		Query:             channel,
-		Count:             cli.Number(2, 100),
-		Page:              cli.Number(3, 1),
+		Count:             cli.Number("count"),
+		Page:              cli.Number("page"),
 		Sort:              "timestamp",
 		SortDir:           "desc",
 		ExcludeMyChannels: false,
@@ -290,7 +585,8 @@ func (cli *CLI) CallConversationsID() int {
 
 	for _, item := range result.Items {
 		if item.Name == channel {
-			return cli.PrintJSON(item.ID)
+			fmt.Fprintf(cli.stdout, "{\"ok\":true, \"id\":\"%s\"}\n", item.ID)
+			return 0
 		}
 	}
 
@@ -299,37 +595,37 @@ func (cli *CLI) CallConversationsID() int {
 
 // CallConversationsInfo sends a http request with the conversations.info action.
 func (cli *CLI) CallConversationsInfo() int {
-	return cli.PrintJSON(cli.api.ConversationsInfo(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.ConversationsInfo(cli.String("room")))
 }
 
 // CallConversationsInvite sends a http request with the conversations.invite action.
 func (cli *CLI) CallConversationsInvite() int {
-	return cli.PrintJSON(cli.api.ConversationsInvite(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.ConversationsInvite(cli.String("room"), cli.String("user")))
 }
 
 // CallConversationsInviteShared sends a http request with the conversations.inviteShared action.
 func (cli *CLI) CallConversationsInviteShared() int {
 	return cli.PrintJSON(cli.api.ConversationsInviteShared(slackapi.ConversationsInviteSharedInput{
-		Channel:         flag.Arg(1),
-		Emails:          strings.Split(flag.Arg(2), ","),
-		ExternalLimited: flag.Arg(3) == "true",
-		UserIDs:         strings.Split(flag.Arg(4), ","),
+		Channel:         cli.String("channel"),
+		Emails:          cli.List("emails"),
+		ExternalLimited: cli.Bool("external_limited"),
+		UserIDs:         cli.List("user_ids"),
 	}))
 }
 
 // CallConversationsJoin sends a http request with the conversations.join action.
 func (cli *CLI) CallConversationsJoin() int {
-	return cli.PrintJSON(cli.api.ConversationsJoin(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.ConversationsJoin(cli.String("room")))
 }
 
 // CallConversationsKick sends a http request with the conversations.kick action.
 func (cli *CLI) CallConversationsKick() int {
-	return cli.PrintJSON(cli.api.ConversationsKick(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.ConversationsKick(cli.String("room"), cli.String("user")))
 }
 
 // CallConversationsLeave sends a http request with the conversations.leave action.
 func (cli *CLI) CallConversationsLeave() int {
-	return cli.PrintJSON(cli.api.ConversationsLeave(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.ConversationsLeave(cli.String("room")))
 }
 
 // CallConversationsList sends a http request with the conversations.list action.
@@ -339,66 +635,72 @@ func (cli *CLI) CallConversationsList() int {
 
 // CallConversationsListConnectInvites sends a http request with the conversations.listConnectInvites action.
 func (cli *CLI) CallConversationsListConnectInvites() int {
-	return cli.PrintJSON(cli.api.ConversationsListConnectInvites(slackapi.ConversationsListConnectInvitesInput{
-		Count:  cli.Number(1, 100),
-		Cursor: flag.Arg(2),
-		TeamID: flag.Arg(3),
-	}))
+	return cli.PrintCursorPages(func(cursor string) interface{} {
+		return cli.api.ConversationsListConnectInvites(slackapi.ConversationsListConnectInvitesInput{
+			Count:  cli.Number("count"),
+			Cursor: cursor,
+			TeamID: cli.String("team_id"),
+		})
+	})
 }
 
 // CallConversationsMark sends a http request with the conversations.mark action.
 func (cli *CLI) CallConversationsMark() int {
 	return cli.PrintJSON(cli.api.ConversationsMark(slackapi.ConversationsMarkInput{
-		Channel:   flag.Arg(1),
-		Timestamp: flag.Arg(2),
+		Channel:   cli.String("room"),
+		Timestamp: cli.String("time"),
 	}))
 }
 
 // CallConversationsMembers sends a http request with the conversations.members action.
 func (cli *CLI) CallConversationsMembers() int {
-	return cli.PrintJSON(cli.api.ConversationsMembers(slackapi.ConversationsMembersInput{
-		Channel: flag.Arg(1),
-		Cursor:  flag.Arg(2),
-		Limit:   cli.Number(3, 100),
-	}))
+	return cli.PrintCursorPages(func(cursor string) interface{} {
+		return cli.api.ConversationsMembers(slackapi.ConversationsMembersInput{
+			Channel: cli.String("channel"),
+			Cursor:  cursor,
+			Limit:   cli.Number("limit"),
+		})
+	})
 }
 
 // CallConversationsOpen sends a http request with the conversations.open action.
 func (cli *CLI) CallConversationsOpen() int {
 	return cli.PrintJSON(cli.api.ConversationsOpen(slackapi.ConversationsOpenInput{
-		Channel:         flag.Arg(1),
-		PreventCreation: flag.Arg(2) == "true",
-		ReturnIm:        flag.Arg(3) == "true",
-		Users:           flag.Arg(4),
+		Channel:         cli.String("channel"),
+		PreventCreation: cli.Bool("prevent_creation"),
+		ReturnIm:        cli.Bool("return_im"),
+		Users:           cli.String("users"),
 	}))
 }
 
 // CallConversationsRename sends a http request with the conversations.rename action.
 func (cli *CLI) CallConversationsRename() int {
-	return cli.PrintJSON(cli.api.ConversationsRename(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.ConversationsRename(cli.String("room"), cli.String("name")))
 }
 
 // CallConversationsReplies sends a http request with the conversations.replies action.
 func (cli *CLI) CallConversationsReplies() int {
-	return cli.PrintJSON(cli.api.ConversationsReplies(slackapi.ConversationsRepliesInput{
-		Channel:   flag.Arg(1),
-		Timestamp: flag.Arg(2),
-		Cursor:    flag.Arg(3),
-		Inclusive: flag.Arg(4) == "true",
-		Latest:    flag.Arg(5),
-		Limit:     cli.Number(6, 1000),
-		Oldest:    flag.Arg(7),
-	}))
+	return cli.PrintCursorPages(func(cursor string) interface{} {
+		return cli.api.ConversationsReplies(slackapi.ConversationsRepliesInput{
+			Channel:   cli.String("channel"),
+			Timestamp: cli.String("ts"),
+			Cursor:    cursor,
+			Inclusive: cli.Bool("inclusive"),
+			Latest:    cli.String("latest"),
+			Limit:     cli.Number("limit"),
+			Oldest:    cli.String("oldest"),
+		})
+	})
 }
 
 // CallConversationsSetPurpose sends a http request with the conversations.setPurpose action.
 func (cli *CLI) CallConversationsSetPurpose() int {
-	return cli.PrintJSON(cli.api.ConversationsSetPurpose(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.ConversationsSetPurpose(cli.String("room"), cli.String("purpose")))
 }
 
 // CallConversationsSetTopic sends a http request with the conversations.setTopic action.
 func (cli *CLI) CallConversationsSetTopic() int {
-	return cli.PrintJSON(cli.api.ConversationsSetTopic(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.ConversationsSetTopic(cli.String("room"), cli.String("topic")))
 }
 
 // CallConversationsSuggestions sends a http request with the conversations.suggestions action.
@@ -408,7 +710,7 @@ func (cli *CLI) CallConversationsSuggestions() int {
 
 // CallConversationsUnarchive sends a http request with the conversations.unarchive action.
 func (cli *CLI) CallConversationsUnarchive() int {
-	return cli.PrintJSON(cli.api.ConversationsUnarchive(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.ConversationsUnarchive(cli.String("room")))
 }
 
 // CallDndEndDnd sends a http request with the dnd.endDnd action.
@@ -423,17 +725,17 @@ func (cli *CLI) CallDndEndSnooze() int {
 
 // CallDndInfo sends a http request with the dnd.info action.
 func (cli *CLI) CallDndInfo() int {
-	return cli.PrintJSON(cli.api.DNDInfo(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.DNDInfo(cli.String("user")))
 }
 
 // CallDndSetSnooze sends a http request with the dnd.setSnooze action.
 func (cli *CLI) CallDndSetSnooze() int {
-	return cli.PrintJSON(cli.api.DNDSetSnooze(cli.Number(1, 60)))
+	return cli.PrintJSON(cli.api.DNDSetSnooze(cli.Number("minutes")))
 }
 
 // CallDndTeamInfo sends a http request with the dnd.teamInfo action.
 func (cli *CLI) CallDndTeamInfo() int {
-	return cli.PrintJSON(cli.api.DNDTeamInfo(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.DNDTeamInfo(cli.String("users")))
 }
 
 // CallEmojiList sends a http request with the emoji.list action.
@@ -443,110 +745,121 @@ func (cli *CLI) CallEmojiList() int {
 
 // CallEventlogHistory sends a http request with the eventlog.history action.
 func (cli *CLI) CallEventlogHistory() int {
-	return cli.PrintJSON(cli.api.EventlogHistory(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.EventlogHistory(cli.String("time")))
 }
 
 // CallFilesCommentsAdd sends a http request with the files.comments.add action.
 func (cli *CLI) CallFilesCommentsAdd() int {
-	return cli.PrintJSON(cli.api.FilesCommentsAdd(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.FilesCommentsAdd(cli.String("file"), cli.String("text")))
 }
 
 // CallFilesCommentsDelete sends a http request with the files.comments.delete action.
 func (cli *CLI) CallFilesCommentsDelete() int {
-	return cli.PrintJSON(cli.api.FilesCommentsDelete(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.FilesCommentsDelete(cli.String("file"), cli.String("fcid")))
 }
 
 // CallFilesCommentsEdit sends a http request with the files.comments.edit action.
 func (cli *CLI) CallFilesCommentsEdit() int {
-	return cli.PrintJSON(cli.api.FilesCommentsEdit(flag.Arg(1), flag.Arg(2), flag.Arg(3)))
+	return cli.PrintJSON(cli.api.FilesCommentsEdit(cli.String("file"), cli.String("fcid"), cli.String("text")))
 }
 
 // CallFilesDelete sends a http request with the files.delete action.
 func (cli *CLI) CallFilesDelete() int {
-	return cli.PrintJSON(cli.api.FilesDelete(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.FilesDelete(cli.String("file")))
 }
 
 // CallFilesInfo sends a http request with the files.info action.
 func (cli *CLI) CallFilesInfo() int {
 	return cli.PrintJSON(cli.api.FilesInfo(
-		flag.Arg(1),
-		cli.Number(2, 1000),
-		cli.Number(3, 1)))
+		cli.String("file"),
+		cli.Number("count"),
+		cli.Number("page")))
 }
 
 // CallFilesList sends a http request with the files.list action.
 func (cli *CLI) CallFilesList() int {
-	return cli.PrintJSON(cli.api.FilesList(slackapi.FileListArgs{
-		Count: cli.Number(1, 1000),
-		Page:  cli.Number(2, 0),
-	}))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.FilesList(slackapi.FileListArgs{
+			Count: cli.Number("count"),
+			Page:  page,
+		})
+	})
 }
 
 // CallFilesListAfterTime sends a http request with the files.listAfterTime action.
 func (cli *CLI) CallFilesListAfterTime() int {
-	return cli.PrintJSON(cli.api.FilesList(slackapi.FileListArgs{
-		TsFrom: flag.Arg(1),
-		Count:  cli.Number(2, 1000),
-		Page:   cli.Number(3, 0),
-	}))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.FilesList(slackapi.FileListArgs{
+			TsFrom: cli.String("time"),
+			Count:  cli.Number("count"),
+			Page:   page,
+		})
+	})
 }
 
 // CallFilesListBeforeTime sends a http request with the files.listBeforeTime action.
 func (cli *CLI) CallFilesListBeforeTime() int {
-	return cli.PrintJSON(cli.api.FilesList(slackapi.FileListArgs{
-		TsTo:  flag.Arg(1),
-		Count: cli.Number(2, 1000),
-		Page:  cli.Number(3, 0),
-	}))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.FilesList(slackapi.FileListArgs{
+			TsTo:  cli.String("time"),
+			Count: cli.Number("count"),
+			Page:  page,
+		})
+	})
 }
 
 // CallFilesListByChannel sends a http request with the files.listByChannel action.
 func (cli *CLI) CallFilesListByChannel() int {
-	return cli.PrintJSON(cli.api.FilesList(slackapi.FileListArgs{
-		Channel: flag.Arg(1),
-		Count:   cli.Number(2, 1000),
-		Page:    cli.Number(3, 0),
-	}))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.FilesList(slackapi.FileListArgs{
+			Channel: cli.String("channel"),
+			Count:   cli.Number("count"),
+			Page:    page,
+		})
+	})
 }
 
 // CallFilesListByType sends a http request with the files.listByType action.
 func (cli *CLI) CallFilesListByType() int {
-	return cli.PrintJSON(cli.api.FilesList(slackapi.FileListArgs{
-		Types: flag.Arg(1),
-		Count: cli.Number(2, 1000),
-		Page:  cli.Number(3, 0),
-	}))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.FilesList(slackapi.FileListArgs{
+			Types: cli.String("type"),
+			Count: cli.Number("count"),
+			Page:  page,
+		})
+	})
 }
 
 // CallFilesListByUser sends a http request with the files.listByUser action.
 func (cli *CLI) CallFilesListByUser() int {
-	return cli.PrintJSON(cli.api.FilesList(slackapi.FileListArgs{
-		User:  flag.Arg(1),
-		Count: cli.Number(2, 1000),
-		Page:  cli.Number(3, 0),
-	}))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.FilesList(slackapi.FileListArgs{
+			User:  cli.String("user"),
+			Count: cli.Number("count"),
+			Page:  page,
+		})
+	})
 }
 
 // CallFilesRevokePublicURL sends a http request with the files.revokePublicURL action.
 func (cli *CLI) CallFilesRevokePublicURL() int {
-	return cli.PrintJSON(cli.api.FilesRevokePublicURL(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.FilesRevokePublicURL(cli.String("file")))
 }
 
 // CallFilesSharedPublicURL sends a http request with the files.sharedPublicURL action.
 func (cli *CLI) CallFilesSharedPublicURL() int {
-	return cli.PrintJSON(cli.api.FilesSharedPublicURL(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.FilesSharedPublicURL(cli.String("file")))
 }
 
 // CallFilesUpload sends a http request with the files.upload action.
 func (cli *CLI) CallFilesUpload() int {
 	var data slackapi.FileUploadArgs
 
-	data.Channels = flag.Arg(1)
-	data.File = "@" + flag.Arg(2)
+	data.Channels = cli.String("channel")
+	data.File = "@" + cli.String("filename")
 
 	if data.File == "@" {
-		fmt.Println("{\"ok\":false, \"error\":\"no file to upload\"}")
-		return 1
+		return cli.PrintError(UsageError("no file to upload"))
 	}
 
 	// grab last part of the file path.
@@ -576,8 +889,8 @@ func (cli *CLI) CallHelpIssuesList() int {
 
 // CallMigrationExchange sends a http request with the migration.exchange action.
 func (cli *CLI) CallMigrationExchange() int {
-	users := strings.Split(flag.Arg(1), ",")
-	order := flag.Arg(2) == "true"
+	users := cli.List("users")
+	order := cli.Bool("order")
 	return cli.PrintJSON(cli.api.MigrationExchange(users, order))
 }
 
@@ -591,70 +904,156 @@ func (cli *CLI) CallPaymentsBillingAddressesValidateAndSet() int {
 	var input slackapi.PaymentsBillingAddressesValidateAndSetInput
 
 	input.CheckoutStep = "form"
-	input.CompanyName = flag.Arg(1)
-	input.Street1 = flag.Arg(2)
-	input.Street2 = flag.Arg(3)
-	input.City = flag.Arg(4)
-	input.State = flag.Arg(5)
-	input.Zip = flag.Arg(6)
-	input.Country = flag.Arg(7)
-	input.VatID = flag.Arg(8)
-	input.AbnID = flag.Arg(9)
-	input.TaxID = flag.Arg(10)
-	input.IsBusiness = flag.Arg(11) == "true"
-	input.IsCheckoutV2 = flag.Arg(12) == "true"
-	input.IsVatRegistered = flag.Arg(13) == "true"
-	input.WaitingForVat = flag.Arg(14) == "true"
-	input.Notes = flag.Arg(15)
+	input.CompanyName = cli.String("company_name")
+	input.Street1 = cli.String("street1")
+	input.Street2 = cli.String("street2")
+	input.City = cli.String("city")
+	input.State = cli.String("state")
+	input.Zip = cli.String("zip")
+	input.Country = cli.String("country")
+	input.VatID = cli.String("vat_id")
+	input.AbnID = cli.String("abn_id")
+	input.TaxID = cli.String("tax_id")
+	input.IsBusiness = cli.Bool("is_business")
+	input.IsCheckoutV2 = cli.Bool("is_checkout_v2")
+	input.IsVatRegistered = cli.Bool("is_vat_registered")
+	input.WaitingForVat = cli.Bool("waiting_for_vat")
+	input.Notes = cli.String("notes")
 
 	return cli.PrintJSON(cli.api.PaymentsBillingAddressesValidateAndSet(input))
 }
 
 // CallPinsAdd sends a http request with the pins.add action.
 func (cli *CLI) CallPinsAdd() int {
-	return cli.PrintJSON(cli.api.PinsAdd(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.PinsAdd(cli.String("channel"), cli.String("item_id")))
 }
 
 // CallPinsList sends a http request with the pins.list action.
 func (cli *CLI) CallPinsList() int {
-	return cli.PrintJSON(cli.api.PinsList(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.PinsList(cli.String("channel")))
 }
 
 // CallPinsRemove sends a http request with the pins.remove action.
 func (cli *CLI) CallPinsRemove() int {
-	return cli.PrintJSON(cli.api.PinsRemove(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.PinsRemove(cli.String("channel"), cli.String("item_id")))
+}
+
+// CallProfileAdd creates or replaces a profile in the configuration file.
+func (cli *CLI) CallProfileAdd() int {
+	profile := Profile{
+		Name:       cli.String("name"),
+		Token:      cli.String("token"),
+		Cookie:     cli.String("cookie"),
+		Channel:    cli.String("default_channel"),
+		RobotName:  cli.String("robot_name"),
+		RobotImage: cli.String("robot_image"),
+		APIURL:     cli.String("api_url"),
+	}
+
+	if profile.Name == "" {
+		return cli.PrintError(UsageError("profile.add; missing profile name"))
+	}
+
+	cli.config.SetProfile(profile)
+
+	if cli.config.CurrentProfile() == "" {
+		cli.config.SetCurrentProfile(profile.Name)
+	}
+
+	if err := cli.config.Save(); err != nil {
+		return cli.PrintError(fmt.Errorf("profile.add; %s", err))
+	}
+
+	return cli.PrintJSON(slackapi.Response{Ok: true})
+}
+
+// CallProfileList prints the profiles in the configuration file. The
+// credentials are masked to prevent accidental leaks.
+func (cli *CLI) CallProfileList() int {
+	var out struct {
+		slackapi.Response
+		Current  string    `json:"current"`
+		Profiles []Profile `json:"profiles"`
+	}
+
+	out.Ok = true
+	out.Current = cli.config.CurrentProfile()
+	out.Profiles = []Profile{}
+
+	for _, name := range cli.config.ProfileNames() {
+		profile := cli.config.Profile(name)
+		profile.Token = maskSecret(profile.Token)
+		profile.Cookie = maskSecret(profile.Cookie)
+		out.Profiles = append(out.Profiles, profile)
+	}
+
+	return cli.PrintJSON(out)
+}
+
+// CallProfileRemove deletes a profile from the configuration file.
+func (cli *CLI) CallProfileRemove() int {
+	name := cli.String("name")
+
+	if !cli.config.HasProfile(name) {
+		return cli.PrintError(NewError(ErrorNotFound, "profile.remove; profile does not exist"))
+	}
+
+	cli.config.RemoveProfile(name)
+
+	if err := cli.config.Save(); err != nil {
+		return cli.PrintError(fmt.Errorf("profile.remove; %s", err))
+	}
+
+	return cli.PrintJSON(slackapi.Response{Ok: true})
+}
+
+// CallProfileUse sets the profile used by default.
+func (cli *CLI) CallProfileUse() int {
+	name := cli.String("name")
+
+	if !cli.config.HasProfile(name) {
+		return cli.PrintError(NewError(ErrorNotFound, "profile.use; profile does not exist"))
+	}
+
+	cli.config.SetCurrentProfile(name)
+
+	if err := cli.config.Save(); err != nil {
+		return cli.PrintError(fmt.Errorf("profile.use; %s", err))
+	}
+
+	return cli.PrintJSON(slackapi.Response{Ok: true})
 }
 
 // CallReactionsAdd sends a http request with the reactions.add action.
 func (cli *CLI) CallReactionsAdd() int {
 	return cli.PrintJSON(cli.api.ReactionsAdd(slackapi.ReactionArgs{
-		Channel:   flag.Arg(1),
-		Timestamp: flag.Arg(2),
-		Name:      flag.Arg(3),
+		Channel:   cli.String("channel"),
+		Timestamp: cli.String("time"),
+		Name:      cli.String("name"),
 	}))
 }
 
 // CallReactionsGet sends a http request with the reactions.get action.
 func (cli *CLI) CallReactionsGet() int {
 	return cli.PrintJSON(cli.api.ReactionsGet(slackapi.ReactionArgs{
-		Channel:   flag.Arg(1),
-		Timestamp: flag.Arg(2),
+		Channel:   cli.String("channel"),
+		Timestamp: cli.String("time"),
 	}))
 }
 
 // CallReactionsList sends a http request with the reactions.list action.
 func (cli *CLI) CallReactionsList() int {
 	return cli.PrintJSON(cli.api.ReactionsList(slackapi.ReactionListArgs{
-		User: flag.Arg(1),
+		User: cli.String("user"),
 	}))
 }
 
 // CallReactionsRemove sends a http request with the reactions.remove action.
 func (cli *CLI) CallReactionsRemove() int {
 	return cli.PrintJSON(cli.api.ReactionsRemove(slackapi.ReactionArgs{
-		Channel:   flag.Arg(1),
-		Timestamp: flag.Arg(2),
-		Name:      flag.Arg(3),
+		Channel:   cli.String("channel"),
+		Timestamp: cli.String("time"),
+		Name:      cli.String("name"),
 	}))
 }
 
@@ -663,8 +1062,7 @@ func (cli *CLI) CallRtmEvents() int {
 	rtm, err := cli.api.NewRTM(slackapi.RTMInput{})
 
 	if err != nil {
-		fmt.Printf("{\"ok\":false, \"error\":\"rtm.events; %s\"}\n", err.Error())
-		return 1
+		return cli.PrintError(fmt.Errorf("rtm.events; %s", err))
 	}
 
 	go rtm.ManageEvents()
@@ -672,16 +1070,17 @@ func (cli *CLI) CallRtmEvents() int {
 	for msg := range rtm.Events {
 		switch event := msg.Data.(type) {
 		case *slackapi.HelloEvent:
-			fmt.Println("hello; connection established")
+			fmt.Fprintln(cli.stdout, "hello; connection established")
 
 		case *slackapi.PresenceChangeEvent:
-			fmt.Println("presence;", event.User, "=>", event.Presence)
+			fmt.Fprintln(cli.stdout, "presence;", event.User, "=>", event.Presence)
 
 		case *slackapi.MessageEvent:
 			if event.Text == "disconnect" {
 				rtm.Disconnect()
 			} else {
-				fmt.Printf(
+				fmt.Fprintf(
+					cli.stdout,
 					"message; %s@%s: %#v\n",
 					event.User,
 					event.Channel,
@@ -690,135 +1089,148 @@ func (cli *CLI) CallRtmEvents() int {
 			}
 
 		case *slackapi.ErrorEvent:
-			fmt.Println("error;", event.Text)
+			fmt.Fprintln(cli.stdout, "error;", event.Text)
 
 		case *slackapi.ReconnectURLEvent:
-			fmt.Println("reconnect;", event.URL)
+			fmt.Fprintln(cli.stdout, "reconnect;", event.URL)
 
 		default:
-			fmt.Printf("%s; %#v\n", msg.Type, msg.Data)
+			fmt.Fprintf(cli.stdout, "%s; %#v\n", msg.Type, msg.Data)
 		}
 	}
 
-	fmt.Println("stopped")
+	fmt.Fprintln(cli.stdout, "stopped")
 	return 0
 }
 
 // CallSignupCheckEmail sends a http request with the signup.checkEmail action.
 func (cli *CLI) CallSignupCheckEmail() int {
-	return cli.PrintJSON(cli.api.SignupCheckEmail(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.SignupCheckEmail(cli.String("email")))
 }
 
 // CallSignupConfirmEmail sends a http request with the signup.confirmEmail action.
 func (cli *CLI) CallSignupConfirmEmail() int {
-	return cli.PrintJSON(cli.api.SignupConfirmEmail(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.SignupConfirmEmail(cli.String("email")))
 }
 
 // CallSearchAll sends a http request with the search.all action.
 func (cli *CLI) CallSearchAll() int {
-	return cli.PrintJSON(cli.api.SearchAll(slackapi.SearchArgs{
-		Query:   flag.Arg(1),
-		Count:   cli.Number(2, 100),
-		Page:    cli.Number(3, 1),
-		Sort:    "timestamp",
-		SortDir: "desc",
-	}))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.SearchAll(slackapi.SearchArgs{
+			Query:   cli.String("query"),
+			Count:   cli.Number("count"),
+			Page:    page,
+			Sort:    "timestamp",
+			SortDir: "desc",
+		})
+	})
 }
 
 // CallSearchChannels sends a http request with the search.modules action.
 func (cli *CLI) CallSearchChannels() int {
-	return cli.PrintJSON(cli.api.SearchModules(slackapi.SearchModulesInput{
-		Module:            "channels",
-		Query:             flag.Arg(1),
-		Count:             cli.Number(2, 100),
-		Page:              cli.Number(3, 1),
-		Sort:              "timestamp",
-		SortDir:           "desc",
-		ExcludeMyChannels: false,
-		ExtraMessageData:  true,
-		Highlight:         false,
-		NoUserProfile:     false,
-	}))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.SearchModules(slackapi.SearchModulesInput{
+			Module:            "channels",
+			Query:             cli.String("query"),
+			Count:             cli.Number("count"),
+			Page:              page,
+			Sort:              "timestamp",
+			SortDir:           "desc",
+			ExcludeMyChannels: false,
+			ExtraMessageData:  true,
+			Highlight:         false,
+			NoUserProfile:     false,
+		})
+	})
 }
 
 // CallSearchFiles sends a http request with the search.files action.
 func (cli *CLI) CallSearchFiles() int {
-	return cli.PrintJSON(cli.api.SearchFiles(slackapi.SearchArgs{
-		Query:   flag.Arg(1),
-		Count:   cli.Number(2, 100),
-		Page:    cli.Number(3, 1),
-		Sort:    "timestamp",
-		SortDir: "desc",
-	}))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.SearchFiles(slackapi.SearchArgs{
+			Query:   cli.String("query"),
+			Count:   cli.Number("count"),
+			Page:    page,
+			Sort:    "timestamp",
+			SortDir: "desc",
+		})
+	})
 }
 
 // CallSearchMessages sends a http request with the search.messages action.
 func (cli *CLI) CallSearchMessages() int {
-	return cli.PrintJSON(cli.api.SearchMessages(slackapi.SearchArgs{
-		Query:   flag.Arg(1),
-		Count:   cli.Number(2, 100),
-		Page:    cli.Number(3, 1),
-		Sort:    "timestamp",
-		SortDir: "desc",
-	}))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.SearchMessages(slackapi.SearchArgs{
+			Query:   cli.String("query"),
+			Count:   cli.Number("count"),
+			Page:    page,
+			Sort:    "timestamp",
+			SortDir: "desc",
+		})
+	})
 }
 
 // CallSearchModules sends a http request with the search.modules action.
 func (cli *CLI) CallSearchModules() int {
-	return cli.PrintJSON(cli.api.SearchModules(slackapi.SearchModulesInput{
-		Module:            flag.Arg(1),
-		Query:             flag.Arg(2),
-		Count:             cli.Number(3, 100),
-		Page:              cli.Number(4, 1),
-		Sort:              "timestamp",
-		SortDir:           "desc",
-		ExcludeMyChannels: false,
-		ExtraMessageData:  true,
-		Highlight:         false,
-		NoUserProfile:     false,
-	}))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.SearchModules(slackapi.SearchModulesInput{
+			Module:            cli.String("module"),
+			Query:             cli.String("query"),
+			Count:             cli.Number("count"),
+			Page:              page,
+			Sort:              "timestamp",
+			SortDir:           "desc",
+			ExcludeMyChannels: false,
+			ExtraMessageData:  true,
+			Highlight:         false,
+			NoUserProfile:     false,
+		})
+	})
 }
 
 // CallSearchUsers sends a http request with the search.messages action.
 func (cli *CLI) CallSearchUsers() int {
 	out, err := cli.api.SearchUsers(slackapi.SearchUsersArgs{
-		Query: flag.Arg(1),
-		Count: cli.Number(2, 100),
+		Query: cli.String("user"),
+		Count: cli.Number("count"),
 	})
 	if err != nil {
-		fmt.Println(err)
-		return 1
+		return cli.PrintError(fmt.Errorf("search.users; %s", err))
 	}
 	return cli.PrintJSON(out)
 }
 
 // CallStarsAdd sends a http request with the stars.add action.
 func (cli *CLI) CallStarsAdd() int {
-	return cli.PrintJSON(cli.api.StarsAdd(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.StarsAdd(cli.String("channel"), cli.String("item_id")))
 }
 
 // CallStarsList sends a http request with the stars.list action.
 func (cli *CLI) CallStarsList() int {
-	return cli.PrintJSON(cli.api.StarsList(cli.Number(1, 1000), cli.Number(2, 1)))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.StarsList(cli.Number("count"), page)
+	})
 }
 
 // CallStarsRemove sends a http request with the stars.remove action.
 func (cli *CLI) CallStarsRemove() int {
-	return cli.PrintJSON(cli.api.StarsRemove(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.StarsRemove(cli.String("channel"), cli.String("item_id")))
 }
 
 // CallTeamAccessLogs sends a http request with the team.accessLogs action.
 func (cli *CLI) CallTeamAccessLogs() int {
-	return cli.PrintJSON(cli.api.TeamAccessLogs(slackapi.TeamAccessLogsInput{
-		Before: flag.Arg(1),
-		Count:  cli.Number(2, 1000),
-		Page:   cli.Number(3, 1),
-	}))
+	return cli.PrintNumberedPages(func(page int) interface{} {
+		return cli.api.TeamAccessLogs(slackapi.TeamAccessLogsInput{
+			Before: cli.String("before"),
+			Count:  cli.Number("count"),
+			Page:   page,
+		})
+	})
 }
 
 // CallTeamBillableInfo sends a http request with the team.billableInfo action.
 func (cli *CLI) CallTeamBillableInfo() int {
-	return cli.PrintJSON(cli.api.TeamBillableInfo(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.TeamBillableInfo(cli.String("team_id"), cli.String("user")))
 }
 
 // CallTeamBillingInfo sends a http request with the team.billing.info action.
@@ -829,8 +1241,8 @@ func (cli *CLI) CallTeamBillingInfo() int {
 // CallTeamChannelsInfo sends a http request with the team.channels.info action.
 func (cli *CLI) CallTeamChannelsInfo() int {
 	return cli.PrintJSON(cli.api.TeamChannelsInfo(slackapi.TeamChannelsInfoInput{
-		TeamID:          flag.Arg(1),
-		ChannelIDs:      strings.Split(flag.Arg(2), ","),
+		TeamID:          cli.String("team_id"),
+		ChannelIDs:      cli.List("channels"),
 		CheckMembership: true,
 	}))
 }
@@ -838,27 +1250,27 @@ func (cli *CLI) CallTeamChannelsInfo() int {
 // CallTeamChannelsMembership sends a http request with the team.channels.membership action.
 func (cli *CLI) CallTeamChannelsMembership() int {
 	return cli.PrintJSON(cli.api.TeamChannelsMembership(slackapi.TeamChannelsMembershipInput{
-		TeamID:  flag.Arg(1),
-		Channel: flag.Arg(2),
-		UserIDs: strings.Split(flag.Arg(3), ","),
+		TeamID:  cli.String("team_id"),
+		Channel: cli.String("channel"),
+		UserIDs: cli.List("users"),
 	}))
 }
 
 // CallTeamInfo sends a http request with the team.info action.
 func (cli *CLI) CallTeamInfo() int {
-	return cli.PrintJSON(cli.api.TeamInfo(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.TeamInfo(cli.String("team")))
 }
 
 // CallTeamIntegrationLogs sends a http request with the team.integrationLogs action.
 func (cli *CLI) CallTeamIntegrationLogs() int {
 	return cli.PrintJSON(cli.api.TeamIntegrationLogs(slackapi.TeamIntegrationLogsInput{
-		AppID:      flag.Arg(1),
-		ChangeType: flag.Arg(2),
-		Count:      flag.Arg(3),
-		Page:       flag.Arg(4),
-		ServiceID:  flag.Arg(5),
-		TeamID:     flag.Arg(6),
-		User:       flag.Arg(7),
+		AppID:      cli.String("app_id"),
+		ChangeType: cli.String("change_type"),
+		Count:      cli.String("count"),
+		Page:       cli.String("page"),
+		ServiceID:  cli.String("service_id"),
+		TeamID:     cli.String("team_id"),
+		User:       cli.String("user"),
 	}))
 }
 
@@ -892,14 +1304,15 @@ func (cli *CLI) CallUsersDeletePhoto() int {
 
 // CallUsersGetPresence sends a http request with the users.getPresence action.
 func (cli *CLI) CallUsersGetPresence() int {
-	return cli.PrintJSON(cli.api.UsersGetPresence(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.UsersGetPresence(cli.String("user")))
 }
 
 // CallUsersID sends a http request with the users.id action.
 func (cli *CLI) CallUsersID() int {
-	fmt.Printf(
+	fmt.Fprintf(
+		cli.stdout,
 		"{\"ok\":true, \"id\":\"%s\"}\n",
-		cli.api.UsersID(flag.Arg(1), cli.Number(2, 100)),
+		cli.api.UsersID(cli.String("user"), cli.Number("limit")),
 	)
 	return 0
 }
@@ -911,17 +1324,19 @@ func (cli *CLI) CallUsersIdentity() int {
 
 // CallUsersInfo sends a http request with the users.info action.
 func (cli *CLI) CallUsersInfo() int {
-	return cli.PrintJSON(cli.api.UsersInfo(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.UsersInfo(cli.String("user")))
 }
 
 // CallUsersList sends a http request with the users.list action.
 func (cli *CLI) CallUsersList() int {
-	return cli.PrintJSON(cli.api.UsersList(cli.Number(1, 100), flag.Arg(2)))
+	return cli.PrintCursorPages(func(cursor string) interface{} {
+		return cli.api.UsersList(cli.Number("limit"), cursor)
+	})
 }
 
 // CallUsersLookupByEmail sends a http request with the users.lookupByEmail action.
 func (cli *CLI) CallUsersLookupByEmail() int {
-	return cli.PrintJSON(cli.api.UsersLookupByEmail(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.UsersLookupByEmail(cli.String("email")))
 }
 
 // CallUsersPrefsGet sends a http request with the users.prefs.get action.
@@ -931,22 +1346,22 @@ func (cli *CLI) CallUsersPrefsGet() int {
 
 // CallUsersPrefsSet sends a http request with the users.prefs.set action.
 func (cli *CLI) CallUsersPrefsSet() int {
-	return cli.PrintJSON(cli.api.UsersPrefsSet(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.UsersPrefsSet(cli.String("name"), cli.String("value")))
 }
 
 // CallUsersPreparePhoto sends a http request with the users.preparePhoto action.
 func (cli *CLI) CallUsersPreparePhoto() int {
-	return cli.PrintJSON(cli.api.UsersPreparePhoto(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.UsersPreparePhoto(cli.String("image")))
 }
 
 // CallUsersProfileGet sends a http request with the users.profile.get action.
 func (cli *CLI) CallUsersProfileGet() int {
-	return cli.PrintJSON(cli.api.UsersProfileGet(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.UsersProfileGet(cli.String("user")))
 }
 
 // CallUsersProfileSet sends a http request with the users.profile.set action.
 func (cli *CLI) CallUsersProfileSet() int {
-	return cli.PrintJSON(cli.api.UsersProfileSet(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.UsersProfileSet(cli.String("name"), cli.String("value")))
 }
 
 // CallUsersSetActive sends a http request with the users.setActive action.
@@ -956,54 +1371,54 @@ func (cli *CLI) CallUsersSetActive() int {
 
 // CallUsersSetAvatar sends a http request with the users.setAvatar action.
 func (cli *CLI) CallUsersSetAvatar() int {
-	return cli.PrintJSON(cli.api.UsersSetAvatar(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.UsersSetAvatar(cli.String("image")))
 }
 
 // CallUsersSetEmail sends a http request with the users.setEmail action.
 func (cli *CLI) CallUsersSetEmail() int {
-	return cli.PrintJSON(cli.api.UsersProfileSet("email", flag.Arg(1)))
+	return cli.PrintJSON(cli.api.UsersProfileSet("email", cli.String("email")))
 }
 
 // CallUsersSetPhoto sends a http request with the users.setPhoto action.
 func (cli *CLI) CallUsersSetPhoto() int {
-	return cli.PrintJSON(cli.api.UsersSetPhoto(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.UsersSetPhoto(cli.String("image_id")))
 }
 
 // CallUsersSetPresence sends a http request with the users.setPresence action.
 func (cli *CLI) CallUsersSetPresence() int {
-	return cli.PrintJSON(cli.api.UsersSetPresence(flag.Arg(1)))
+	return cli.PrintJSON(cli.api.UsersSetPresence(cli.String("presence")))
 }
 
 // CallUsersSetStatus sends a http request with the users.setStatus action.
 func (cli *CLI) CallUsersSetStatus() int {
-	return cli.PrintJSON(cli.api.UsersSetStatus(flag.Arg(1), flag.Arg(2)))
+	return cli.PrintJSON(cli.api.UsersSetStatus(cli.String("emoji"), cli.String("text")))
 }
 
 // CallUsersSetUsername sends a http request with the users.setUsername action.
 func (cli *CLI) CallUsersSetUsername() int {
-	return cli.PrintJSON(cli.api.UsersProfileSet("username", flag.Arg(1)))
+	return cli.PrintJSON(cli.api.UsersProfileSet("username", cli.String("username")))
 }
 
 // CallWorkflowsStepCompleted sends a http request with the workflows.stepCompleted action.
 func (cli *CLI) CallWorkflowsStepCompleted() int {
 	return cli.PrintJSON(cli.api.WorkflowsStepCompleted(slackapi.WorkflowsStepCompletedInput{
-		WorkflowStepExecuteID: flag.Arg(1),
+		WorkflowStepExecuteID: cli.String("workflow_step_execute_id"),
 	}))
 }
 
 // CallWorkflowsStepFailed sends a http request with the workflows.stepFailed action.
 func (cli *CLI) CallWorkflowsStepFailed() int {
 	return cli.PrintJSON(cli.api.WorkflowsStepFailed(slackapi.WorkflowsStepFailedInput{
-		WorkflowStepExecuteID: flag.Arg(1),
-		Error:                 slackapi.WorkflowError{Message: flag.Arg(2)},
+		WorkflowStepExecuteID: cli.String("workflow_step_execute_id"),
+		Error:                 slackapi.WorkflowError{Message: cli.String("error")},
 	}))
 }
 
 // CallWorkflowsUpdateStep sends a http request with the workflows.updateStep action.
 func (cli *CLI) CallWorkflowsUpdateStep() int {
 	return cli.PrintJSON(cli.api.WorkflowsUpdateStep(slackapi.WorkflowsUpdateStepInput{
-		WorkflowStepExecuteID: flag.Arg(1),
-		StepImageURL:          flag.Arg(2),
-		StepName:              flag.Arg(3),
+		WorkflowStepExecuteID: cli.String("workflow_step_edit_id"),
+		StepImageURL:          cli.String("step_image_url"),
+		StepName:              cli.String("step_name"),
 	}))
 }
@@ -2,6 +2,9 @@ module github.com/cixtor/slackcli
 
 go 1.17
 
-require github.com/cixtor/slackapi v1.0.2
+require (
+	github.com/cixtor/slackapi v1.0.2
+	golang.org/x/crypto v0.7.0
+)
 
 require golang.org/x/net v0.8.0 // indirect
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// docsURL is the address of the documentation of the web API methods.
+const docsURL = "https://api.slack.com/methods/"
+
+// CommandDoc is the documentation of a command, used by the help pages and
+// the man pages.
+type CommandDoc struct {
+	Name    string
+	Usage   string
+	Help    string
+	Params  []ParamDoc
+	Scopes  string
+	Example string
+	URL     string
+}
+
+// ParamDoc is the documentation of a command parameter.
+type ParamDoc struct {
+	Name    string
+	Type    string
+	Default string
+	Help    string
+}
+
+// wantsHelp checks if the arguments of a command ask for its help page.
+func wantsHelp(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+
+		if arg == "-h" || arg == "-help" || arg == "--help" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// commandUsage returns the name of the command followed by its parameters.
+func commandUsage(command Command) string {
+	usage := "slackcli " + command.Name
+
+	for _, param := range command.Params {
+		if param.Type == TypeRest {
+			usage += " [" + param.Name + "...]"
+			continue
+		}
+
+		usage += " [" + param.Name + "]"
+	}
+
+	return usage
+}
+
+// Document collects the documentation of a command.
+func Document(command Command) CommandDoc {
+	doc := CommandDoc{
+		Name:    command.Name,
+		Usage:   commandUsage(command),
+		Help:    command.Help,
+		Scopes:  commandScopes[command.Name],
+		Example: commandExample(command),
+	}
+
+	method, ok := commandMethods[command.Name]
+
+	if !ok {
+		method = command.Name
+	}
+
+	if method != "" {
+		doc.URL = docsURL + method
+	}
+
+	for _, param := range command.Params {
+		item := ParamDoc{
+			Name: param.Name,
+			Type: paramType(param),
+			Help: lookupDoc(paramHelp, command.Name, param.Name),
+		}
+
+		if param.Default != "" && param.Default != "0" && param.Default != "false" {
+			item.Default = param.Default
+		}
+
+		if len(param.Values) > 0 {
+			item.Help += ", one of: " + strings.Join(param.Values, ", ")
+		}
+
+		if param.Resolve == ResolveMessage || param.Resolve == ResolveThread {
+			item.Help += ", or the URL of the message"
+		}
+
+		if param.Input {
+			item.Help += ", \"-\" reads stdin and \"@path\" reads a file"
+		}
+
+		doc.Params = append(doc.Params, item)
+	}
+
+	return doc
+}
+
+// paramType describes the kind of value accepted by a parameter.
+func paramType(param Param) string {
+	switch {
+	case param.Type == TypeInt:
+		return "int"
+	case param.Type == TypeBool:
+		return "bool"
+	case param.Type == TypeRest:
+		return "args..."
+	case len(param.Values) > 0:
+		return "enum"
+	case param.Type == TypeList && param.Resolve == ResolveChannel:
+		return "channels"
+	case param.Type == TypeList && param.Resolve == ResolveUser:
+		return "users"
+	case param.Type == TypeList:
+		return "list"
+	case param.Resolve == ResolveChannel:
+		return "channel"
+	case param.Resolve == ResolveUser:
+		return "user"
+	case param.Resolve == ResolveMessage || param.Resolve == ResolveThread:
+		return "ts"
+	}
+
+	return "string"
+}
+
+// lookupDoc returns the entry for a parameter of a command, or the generic
+// entry for parameters with the same name.
+func lookupDoc(table map[string]string, command string, name string) string {
+	if text, ok := table[command+"\x20"+name]; ok {
+		return text
+	}
+
+	return table[name]
+}
+
+// commandExample builds an invocation of the command with the parameters in
+// order, up to the first one without an example value. Numbers and booleans
+// are left out because they have defaults.
+func commandExample(command Command) string {
+	example := "slackcli " + command.Name
+
+	for _, param := range command.Params {
+		if param.Type == TypeInt || param.Type == TypeBool {
+			continue
+		}
+
+		value := lookupDoc(paramExample, command.Name, param.Name)
+
+		if value == "" && len(param.Values) > 0 {
+			value = param.Values[0]
+		}
+
+		if value == "" {
+			if example == "slackcli "+command.Name {
+				example += " <" + param.Name + ">"
+			}
+
+			break
+		}
+
+		example += "\x20" + value
+	}
+
+	return example
+}
+
+// printCommandHelp prints the help page of a command.
+func (cli *CLI) printCommandHelp(command Command) {
+	doc := Document(command)
+
+	fmt.Fprintf(cli.stdout, "Usage:\n  %s\n\n%s\n", doc.Usage, doc.Help)
+
+	if len(doc.Params) > 0 {
+		fmt.Fprintf(cli.stdout, "\nParameters:\n")
+
+		writer := tabwriter.NewWriter(cli.stdout, 0, 8, 2, '\x20', 0)
+
+		for _, param := range doc.Params {
+			text := param.Help
+
+			if param.Default != "" {
+				text += fmt.Sprintf(" (default %s)", param.Default)
+			}
+
+			fmt.Fprintf(writer, "  --%s\t%s\t%s\n", param.Name, param.Type, text)
+		}
+
+		writer.Flush()
+	}
+
+	if doc.Scopes != "" {
+		fmt.Fprintf(cli.stdout, "\nScopes:\n  %s\n", doc.Scopes)
+	}
+
+	fmt.Fprintf(cli.stdout, "\nExample:\n  %s\n", doc.Example)
+
+	if doc.URL != "" {
+		fmt.Fprintf(cli.stdout, "\nDocumentation:\n  %s\n", doc.URL)
+	}
+}
+
+// CallMan prints the man page of the program, with every command. With a
+// folder, it also writes one man page per command into the folder, named
+// like slackcli-chat.postMessage.1, to install them under man1.
+func (cli *CLI) CallMan() int {
+	dir := cli.String("dir")
+
+	if dir == "" {
+		cli.stdout.Write(cli.manPage())
+		return ExitSuccess
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return cli.PrintError(fmt.Errorf("man; %s", err))
+	}
+
+	pages := map[string][]byte{"slackcli.1": cli.manPage()}
+
+	for _, command := range cli.commands {
+		pages["slackcli-"+command.Name+".1"] = commandManPage(Document(command))
+	}
+
+	for name, page := range pages {
+		if err := os.WriteFile(filepath.Join(dir, name), page, 0644); err != nil {
+			return cli.PrintError(fmt.Errorf("man; %s", err))
+		}
+	}
+
+	fmt.Fprintf(cli.stdout, "{\"ok\":true, \"dir\":%q, \"pages\":%d}\n", dir, len(pages))
+
+	return ExitSuccess
+}
+
+// manPage returns the man page of the program in the roff format.
+func (cli *CLI) manPage() []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(".TH SLACKCLI 1 \"\" \"slackcli\" \"Slack CLI\"\n")
+	buf.WriteString(".SH NAME\nslackcli \\- low level Slack API client\n")
+	buf.WriteString(".SH SYNOPSIS\n.B slackcli\n[\\fIflags\\fR] \\fIcommand\\fR [\\fIparameters\\fR]\n")
+	buf.WriteString(".SH DESCRIPTION\n")
+	buf.WriteString("Low level client of the Slack web API. Parameters are passed by position, in the order of the synopsis of each command, or by name, like \\-\\-channel=general.\n")
+	buf.WriteString(".SH OPTIONS\n")
+
+	cli.flags.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(&buf, ".TP\n.B \\-%s\n%s\n", roff(f.Name), roff(f.Usage))
+	})
+
+	buf.WriteString(".SH COMMANDS\n")
+
+	for _, command := range cli.commands {
+		writeManCommand(&buf, true, Document(command))
+	}
+
+	buf.WriteString(".SH SEE ALSO\nhttps://api.slack.com/methods\n")
+
+	return buf.Bytes()
+}
+
+// commandManPage returns the man page of a command in the roff format.
+func commandManPage(doc CommandDoc) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, ".TH SLACKCLI\\-%s 1 \"\" \"slackcli\" \"Slack CLI\"\n", roff(strings.ToUpper(doc.Name)))
+	fmt.Fprintf(&buf, ".SH NAME\nslackcli\\-%s \\- %s\n", roff(doc.Name), roff(doc.Help))
+
+	writeManCommand(&buf, false, doc)
+
+	buf.WriteString(".SH SEE ALSO\nslackcli(1)\n")
+
+	return buf.Bytes()
+}
+
+// writeManCommand writes the documentation of a command, in sections of its
+// own page, or in paragraphs of a subsection of the page of the program.
+func writeManCommand(buf *bytes.Buffer, inline bool, doc CommandDoc) {
+	section := func(title string) {
+		if inline {
+			fmt.Fprintf(buf, ".PP\n.I %s\n.br\n", title)
+			return
+		}
+
+		fmt.Fprintf(buf, ".SH %s\n", strings.ToUpper(title))
+	}
+
+	if inline {
+		fmt.Fprintf(buf, ".SS %s\n%s\n", roff(doc.Name), roff(doc.Help))
+	}
+
+	section("Synopsis")
+	fmt.Fprintf(buf, ".B %s\n", roff(doc.Usage))
+
+	if len(doc.Params) > 0 {
+		section("Parameters")
+
+		for _, param := range doc.Params {
+			text := param.Help
+
+			if param.Default != "" {
+				text += fmt.Sprintf(" (default %s)", param.Default)
+			}
+
+			fmt.Fprintf(buf, ".TP\n.BR \\-\\-%s \" \" \\fI%s\\fR\n%s\n", roff(param.Name), roff(param.Type), roff(text))
+		}
+	}
+
+	if doc.Scopes != "" {
+		section("Scopes")
+		fmt.Fprintf(buf, "%s\n", roff(doc.Scopes))
+	}
+
+	section("Example")
+	fmt.Fprintf(buf, ".nf\n%s\n.fi\n", roff(doc.Example))
+
+	if doc.URL != "" {
+		section("Documentation")
+		fmt.Fprintf(buf, "%s\n", roff(doc.URL))
+	}
+}
+
+// roff escapes the text for a man page.
+func roff(text string) string {
+	text = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(text)
+
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+
+	return text
+}
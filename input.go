@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readInputs replaces the value of the text parameters of a command with the
+// content of stdin, if the value is "-", or with the content of a file, if
+// the value is "@path". Values like "@here" that do not point to a file are
+// sent as they are. A secret passed as an argument prints a warning.
+func (cli *CLI) readInputs(command Command) error {
+	var stdinParam string
+
+	for _, param := range command.Params {
+		value := cli.params[param.Name]
+
+		if !param.Input || value == "" {
+			continue
+		}
+
+		if value == "-" {
+			if stdinParam != "" {
+				return fmt.Errorf("%s and %s cannot both read from stdin", stdinParam, param.Name)
+			}
+
+			text, err := cli.readStdin()
+
+			if err != nil {
+				return fmt.Errorf("%s: %s", param.Name, err)
+			}
+
+			stdinParam = param.Name
+			cli.params[param.Name] = text
+			continue
+		}
+
+		if !strings.HasPrefix(value, "@") {
+			if param.Secret {
+				fmt.Fprintf(cli.stderr, "warning: the %s is saved in the shell history, use - to read it from stdin\n", param.Name)
+			}
+
+			continue
+		}
+
+		text, err := readInputFile(value[1:])
+
+		if err != nil {
+			return fmt.Errorf("%s: %s", param.Name, err)
+		}
+
+		if text != nil {
+			cli.params[param.Name] = *text
+		}
+	}
+
+	return nil
+}
+
+// readStdin returns the content of stdin without the trailing line breaks.
+func (cli *CLI) readStdin() (string, error) {
+	if cli.stdin == nil {
+		return "", errors.New("stdin is not available here, use @path to read a file")
+	}
+
+	data, err := io.ReadAll(cli.stdin)
+
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// readInputFile returns the content of a file without the trailing line
+// breaks. If the file does not exist, it returns nil for words like @here or
+// @channel or @john.doe, which are sent as they are, and an error for paths
+// like @blocks.json or @./message, which are more likely a typo.
+func readInputFile(path string) (*string, error) {
+	info, err := os.Stat(path)
+
+	if os.IsPermission(err) {
+		return nil, err
+	}
+
+	if looksLikePath(path) {
+		if err != nil {
+			return nil, err
+		}
+
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory", path)
+		}
+	}
+
+	if err != nil || info.IsDir() {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimRight(string(data), "\r\n")
+
+	return &text, nil
+}
+
+// inputExtensions are the extensions of the files that are usually passed
+// with @path, a missing file with one of them is reported as an error.
+var inputExtensions = []string{".json", ".txt", ".md"}
+
+// looksLikePath returns true if the value after the @ has a slash, or ends
+// with the extension of a text file, and no spaces, like a sentence. Values
+// like @john.doe or @v1.2 are mentions or versions rather than files.
+func looksLikePath(value string) bool {
+	if strings.ContainsAny(value, "\x20\t\n") {
+		return false
+	}
+
+	if strings.Contains(value, "/") {
+		return true
+	}
+
+	for _, ext := range inputExtensions {
+		if strings.HasSuffix(strings.ToLower(value), ext) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RawArgs contains the arguments of a request to any method of the web API
+// service. Values are sent as text, JSON values are sent as they are when the
+// request has a JSON body, and files are sent in a multipart form.
+type RawArgs struct {
+	Values map[string]string
+	JSON   map[string]json.RawMessage
+	Files  map[string]string
+}
+
+// ParseRawArgs reads the arguments of the call command:
+//
+//	key=value   sends the value as text
+//	key:=json   sends the value as JSON, like limit:=10 or blocks:='[...]'
+//	key=@path   uploads the file at path
+//	@path       reads the arguments from a JSON object in a file
+func ParseRawArgs(args []string) (RawArgs, error) {
+	raw := RawArgs{
+		Values: map[string]string{},
+		JSON:   map[string]json.RawMessage{},
+		Files:  map[string]string{},
+	}
+
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "@") {
+			if err := raw.load(arg[1:]); err != nil {
+				return raw, err
+			}
+
+			continue
+		}
+
+		eq := strings.Index(arg, "=")
+
+		if eq <= 0 {
+			return raw, fmt.Errorf("invalid argument %q, use key=value, key:=json or @file", arg)
+		}
+
+		key, value := arg[:eq], arg[eq+1:]
+
+		switch {
+		case strings.HasSuffix(key, ":"):
+			if !json.Valid([]byte(value)) {
+				return raw, fmt.Errorf("invalid JSON in %s", key)
+			}
+
+			raw.JSON[strings.TrimSuffix(key, ":")] = json.RawMessage(value)
+
+		case strings.HasPrefix(value, "@"):
+			raw.Files[key] = value[1:]
+
+		default:
+			raw.Values[key] = value
+		}
+	}
+
+	return raw, nil
+}
+
+// load reads arguments from a JSON object in a file.
+func (raw *RawArgs) load(filename string) error {
+	var object map[string]json.RawMessage
+
+	data, err := os.ReadFile(filename)
+
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("%s: %s", filename, err)
+	}
+
+	for key, value := range object {
+		var text string
+
+		if json.Unmarshal(value, &text) == nil {
+			raw.Values[key] = text
+			continue
+		}
+
+		raw.JSON[key] = value
+	}
+
+	return nil
+}
+
+// form returns the arguments as text, JSON values are sent verbatim.
+func (raw RawArgs) form() url.Values {
+	form := url.Values{}
+
+	for key, value := range raw.Values {
+		form.Set(key, value)
+	}
+
+	for key, value := range raw.JSON {
+		form.Set(key, string(value))
+	}
+
+	return form
+}
+
+// Invoke sends a request to any method of the web API service using the
+// credentials of the active profile. The request goes through the same HTTP
+// transport as the other commands, so it is retried, redirected to another
+// API URL or recorded like them. Use GET to send the arguments in the query
+// string; otherwise the arguments are sent in a multipart form if there are
+// files, as a JSON object if there are JSON values, or as a regular form.
+func (cli *CLI) Invoke(httpMethod string, method string, raw RawArgs) (map[string]interface{}, error) {
+	var body io.Reader
+	var contentType string
+
+	endpoint := DefaultAPIURL + method
+
+	switch {
+	case httpMethod == http.MethodGet:
+		if len(raw.Files) > 0 {
+			return nil, fmt.Errorf("files cannot be sent with GET")
+		}
+
+		endpoint += "?" + raw.form().Encode()
+
+	case len(raw.Files) > 0:
+		var buf bytes.Buffer
+
+		writer := multipart.NewWriter(&buf)
+
+		for key, values := range raw.form() {
+			writer.WriteField(key, values[0])
+		}
+
+		for _, key := range sortedFileKeys(raw.Files) {
+			if err := attachFile(writer, key, raw.Files[key]); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+
+		body = &buf
+		contentType = writer.FormDataContentType()
+
+	case len(raw.JSON) > 0:
+		object := map[string]interface{}{}
+
+		for key, value := range raw.Values {
+			object[key] = value
+		}
+
+		for key, value := range raw.JSON {
+			object[key] = value
+		}
+
+		data, err := json.Marshal(object)
+
+		if err != nil {
+			return nil, err
+		}
+
+		body = bytes.NewReader(data)
+		contentType = "application/json; charset=utf-8"
+
+	default:
+		body = strings.NewReader(raw.form().Encode())
+		contentType = "application/x-www-form-urlencoded"
+	}
+
+	req, err := http.NewRequest(httpMethod, endpoint, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if cli.profile.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cli.profile.Token)
+	}
+
+	if cookie := cli.profile.Cookie; cookie != "" {
+		if !strings.Contains(cookie, "=") {
+			cookie = "d=" + cookie
+		}
+
+		req.Header.Set("Cookie", cookie)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&object); err != nil {
+		if res.StatusCode == http.StatusTooManyRequests {
+			return nil, NewError(ErrorRateLimit, "ratelimited")
+		}
+
+		return nil, fmt.Errorf("unexpected response with status %q", res.Status)
+	}
+
+	return object, nil
+}
+
+// InvokeResult sends a request with the arguments that are not empty to a
+// method without a function in the library. Errors are returned as a failed
+// response, so the result is printed like the responses of the library.
+func (cli *CLI) InvokeResult(method string, values map[string]string) interface{} {
+	raw := RawArgs{Values: map[string]string{}}
+
+	for key, value := range values {
+		if value != "" {
+			raw.Values[key] = value
+		}
+	}
+
+	res, err := cli.Invoke(http.MethodPost, method, raw)
+
+	if err != nil {
+		return map[string]interface{}{"ok": false, "error": method + "; " + err.Error()}
+	}
+
+	return res
+}
+
+// attachFile adds a file to a multipart form.
+func attachFile(writer *multipart.Writer, key string, filename string) error {
+	file, err := os.Open(filename)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	part, err := writer.CreateFormFile(key, filepath.Base(filename))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(part, file)
+
+	return err
+}
+
+func sortedFileKeys(files map[string]string) []string {
+	keys := make([]string, 0, len(files))
+
+	for key := range files {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -3,170 +3,264 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"time"
 )
 
-var debugMode bool
+// offlineCommands do not send requests to the web API service, so they run
+// without looking for the credentials, which may ask for a passphrase.
+var offlineCommands = map[string]bool{"completion": true, "help": true, "man": true}
 
 func main() {
-	flag.BoolVar(&debugMode, "debug", false, "Instructs slackapi to print all HTTP requests")
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the program with the command line arguments, without the name
+// of the program, and the standard streams. It returns the Unix exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("slackcli", flag.ContinueOnError)
+
+	flags.SetOutput(stderr)
+
+	debugMode := flags.Bool("debug", false, "Instructs slackapi to print all HTTP requests")
+	profileName := flags.String("profile", os.Getenv("SLACK_PROFILE"), "Name of the profile in the configuration file to use")
+	outputFormat := flags.String("output", "json", "Output format: json, json-compact, ndjson, yaml, csv, tsv, table")
+	outputColumns := flags.String("columns", "", "Comma-separated fields printed by the csv, tsv and table formats")
+	outputTemplate := flags.String("template", "", "Go template used to print the response, e.g. '{{range .members}}{{.id}}{{\"\\n\"}}{{end}}'")
+	outputTemplateFile := flags.String("template-file", "", "File with the Go template used to print the response")
+	outputQuery := flags.String("query", "", "Filter applied to the response, e.g. '.channels[] | select(.is_private == false) | .name'")
+	apiURL := flags.String("api-url", os.Getenv("SLACK_API_URL"), "Address of the web API service, e.g. http://localhost:8080/api/")
+	recordDir := flags.String("record", "", "Folder where every HTTP request and response is saved, with the secrets redacted")
+	replayDir := flags.String("replay", "", "Folder with the HTTP interactions saved by -record, used instead of the network")
+	maxRetries := flags.Int("max-retries", 3, "Number of times a request is retried after a rate limit, server or network error")
+	retryTimeout := flags.Duration("retry-timeout", time.Minute, "Maximum time spent waiting to retry a request")
 
 	cli := NewCLI()
 
-	cli.AutoAuthenticate()
-
-	cli.Register(cli.CallAPITest, "api.test", []string{"error"}, "Checks API calling code")
-	cli.Register(cli.CallAPIGetFlannelHTTPURL, "api.getFlannelHttpUrl", []string{}, "Gets the organization's canonical API endpoint")
-	cli.Register(cli.CallAppsConnectionsOpen, "apps.connections.open", []string{}, "Generate a temporary Socket Mode WebSocket URL that your app can connect to in order to receive events and interactive payloads over")
-	cli.Register(cli.CallAppsEventAuthorizationsList, "apps.event.authorizations.list", []string{"event_context", "cursor", "limit"}, "Get a list of authorizations for the given event context. Each authorization represents an app installation that the event is visible to")
-	cli.Register(cli.CallAppsList, "apps.list", []string{}, "Lists associated applications")
-	cli.Register(cli.CallAppsManifestCreate, "apps.manifest.create", []string{"manifest"}, "Create an app from an app manifest")
-	cli.Register(cli.CallAppsManifestDelete, "apps.manifest.delete", []string{"app_id"}, "Permanently deletes an app created through app manifests")
-	cli.Register(cli.CallAppsManifestExport, "apps.manifest.export", []string{"app_id"}, "Export an app manifest from an existing app")
-	cli.Register(cli.CallAppsManifestUpdate, "apps.manifest.update", []string{"app_id", "manifest"}, "Update an app from an app manifest")
-	cli.Register(cli.CallAppsManifestValidate, "apps.manifest.validate", []string{"manifest", "app_id"}, "Validate an app manifest")
-	cli.Register(cli.CallAuthRevoke, "auth.revoke", []string{"test"}, "Revokes a token")
-	cli.Register(cli.CallAuthTeamsList, "auth.teams.list", []string{"cursor", "include_icon", "limit"}, "List the workspaces a token can access")
-	cli.Register(cli.CallAuthTest, "auth.test", []string{}, "Checks authentication and identity")
-	cli.Register(cli.CallBotsInfo, "bots.info", []string{"bot"}, "Gets information about a bot user")
-	cli.Register(cli.CallChatDelete, "chat.delete", []string{"channel", "time"}, "Deletes a message")
-	cli.Register(cli.CallChatDeleteAttachment, "chat.deleteAttachment", []string{"channel", "time", "attachment"}, "Deletes a message attachment")
-	cli.Register(cli.CallChatMeMessage, "chat.meMessage", []string{"channel", "text"}, "Share a me message into a channel")
-	cli.Register(cli.CallChatPostAttachment, "chat.postAttachment", []string{"channel", "json"}, "Sends an attachment to a channel")
-	cli.Register(cli.CallChatPostMessage, "chat.postMessage", []string{"channel", "text"}, "Sends a message to a channel")
-	cli.Register(cli.CallChatRobotMessage, "chat.robotMessage", []string{"channel", "text"}, "Sends a message to a channel as a robot")
-	cli.Register(cli.CallChatUpdate, "chat.update", []string{"channel", "time", "text"}, "Updates a message")
-	cli.Register(cli.CallClientCounts, "client.counts", []string{}, "List mentions in different conversations")
-	cli.Register(cli.CallClientShouldReload, "client.shouldReload", []string{"team_ids", "version_ts", "build_version_ts", "config_version_ts"}, "Determine if the Slack client must reload or not")
-	cli.Register(cli.CallConversationsAcceptSharedInvite, "conversations.acceptSharedInvite", []string{"channel_name", "channel_id", "free_trial_accepted", "invite_id", "is_private", "team_id"}, "Accepts an invitation to a Slack Connect channel")
-	cli.Register(cli.CallConversationsApproveSharedInvite, "conversations.approveSharedInvite", []string{"invite_id", "target_team"}, "Approves an invitation to a Slack Connect channel")
-	cli.Register(cli.CallConversationsArchive, "conversations.archive", []string{"room"}, "Archives a conversation")
-	cli.Register(cli.CallConversationsClose, "conversations.close", []string{"room"}, "Closes a direct message or multi-person direct message")
-	cli.Register(cli.CallConversationsCreate, "conversations.create", []string{"name", "is_private", "team_id"}, "Initiates a public or private channel-based conversation")
-	cli.Register(cli.CallConversationsDeclineSharedInvite, "conversations.declineSharedInvite", []string{"invite_id", "target_team"}, "Declines a Slack Connect channel invite")
-	cli.Register(cli.CallConversationsDelete, "conversations.delete", []string{"channel"}, "Delete a public or private channel")
-	cli.Register(cli.CallConversationsGenericInfo, "conversations.genericInfo", []string{"channels"}, "Retrieve information about various channels")
-	cli.Register(cli.CallConversationsHistory, "conversations.history", []string{"room", "time"}, "Fetches a conversation's history of messages and events")
-	cli.Register(cli.CallConversationsID, "conversations.id", []string{"room"}, "Prints the conversation ID fo the specified room")
-	cli.Register(cli.CallConversationsInfo, "conversations.info", []string{"room"}, "Retrieve information about a conversation")
-	cli.Register(cli.CallConversationsInvite, "conversations.invite", []string{"room", "user"}, "Invites users to a channel")
-	cli.Register(cli.CallConversationsInviteShared, "conversations.inviteShared", []string{"channel", "emails", "external_limited", "user_ids"}, "Sends an invitation to a Slack Connect channel")
-	cli.Register(cli.CallConversationsJoin, "conversations.join", []string{"room"}, "Joins an existing conversation")
-	cli.Register(cli.CallConversationsKick, "conversations.kick", []string{"room", "user"}, "Removes a user from a conversation")
-	cli.Register(cli.CallConversationsLeave, "conversations.leave", []string{"room"}, "Leaves a conversation")
-	cli.Register(cli.CallConversationsList, "conversations.list", []string{}, "Lists all channels in a Slack team")
-	cli.Register(cli.CallConversationsListConnectInvites, "conversations.listConnectInvites", []string{"count", "cursor", "team_id"}, "Lists shared channel invites that have been generated or received but have not been approved by all parties")
-	cli.Register(cli.CallConversationsMark, "conversations.mark", []string{"room", "time"}, "Sets the read cursor in a channel")
-	cli.Register(cli.CallConversationsMembers, "conversations.members", []string{"channel", "cursor", "limit"}, "Retrieve members of a conversation")
-	cli.Register(cli.CallConversationsOpen, "conversations.open", []string{"channel", "prevent_creation", "return_im", "users"}, "Opens or resumes a direct message or multi-person direct message")
-	cli.Register(cli.CallConversationsRename, "conversations.rename", []string{"room", "name"}, "Renames a conversation")
-	cli.Register(cli.CallConversationsReplies, "conversations.replies", []string{"channel", "ts", "cursor", "inclusive", "latest", "limit", "oldest"}, "Retrieve a thread of messages posted to a conversation")
-	cli.Register(cli.CallConversationsSetPurpose, "conversations.setPurpose", []string{"room", "purpose"}, "Sets the purpose for a conversation")
-	cli.Register(cli.CallConversationsSetTopic, "conversations.setTopic", []string{"room", "topic"}, "Sets the topic for a conversation")
-	cli.Register(cli.CallConversationsSuggestions, "conversations.suggestions", []string{}, "List Slack suggestions to join conversations")
-	cli.Register(cli.CallConversationsUnarchive, "conversations.unarchive", []string{"room"}, "Reverses conversation archival")
-	cli.Register(cli.CallDndEndDnd, "dnd.endDnd", []string{}, "Ends the current user's \"Do Not Disturb\" session immediately")
-	cli.Register(cli.CallDndEndSnooze, "dnd.endSnooze", []string{}, "Ends the current user's snooze mode immediately")
-	cli.Register(cli.CallDndInfo, "dnd.info", []string{"user"}, "Retrieves a user's current \"Do Not Disturb\" status")
-	cli.Register(cli.CallDndSetSnooze, "dnd.setSnooze", []string{}, "Ends the current user's snooze mode immediately")
-	cli.Register(cli.CallDndTeamInfo, "dnd.teamInfo", []string{"users"}, "Retrieves the \"Do Not Disturb\" status for users on a team")
-	cli.Register(cli.CallEmojiList, "emoji.list", []string{}, "Lists custom emoji for a team")
-	cli.Register(cli.CallEventlogHistory, "eventlog.history", []string{"time"}, "Lists all the events since the specified time")
-	cli.Register(cli.CallFilesCommentsAdd, "files.comments.add", []string{"file", "text"}, "Add a comment to an existing file")
-	cli.Register(cli.CallFilesCommentsDelete, "files.comments.delete", []string{"file", "fcid"}, "Deletes an existing comment on a file")
-	cli.Register(cli.CallFilesCommentsEdit, "files.comments.edit", []string{"file", "fcid", "text"}, "Edit an existing file comment")
-	cli.Register(cli.CallFilesDelete, "files.delete", []string{"file"}, "Deletes a file and associated comments")
-	cli.Register(cli.CallFilesInfo, "files.info", []string{"file", "count", "page"}, "Gets information about a team file")
-	cli.Register(cli.CallFilesList, "files.list", []string{"count", "page"}, "Lists and filters team files")
-	cli.Register(cli.CallFilesListAfterTime, "files.listAfterTime", []string{"time", "count", "page"}, "Lists and filters team files after this timestamp (inclusive)")
-	cli.Register(cli.CallFilesListBeforeTime, "files.listBeforeTime", []string{"time", "count", "page"}, "Lists and filters team files before this timestamp (inclusive)")
-	cli.Register(cli.CallFilesListByChannel, "files.listByChannel", []string{"channel", "count", "page"}, "Lists and filters team files in a specific channel")
-	cli.Register(cli.CallFilesListByType, "files.listByType", []string{"type", "count", "page"}, "Lists and filters team files by type: all, posts, snippets, images, gdocs, zips, pdfs")
-	cli.Register(cli.CallFilesListByUser, "files.listByUser", []string{"user", "count", "page"}, "Lists and filters team files created by a single user")
-	cli.Register(cli.CallFilesRevokePublicURL, "files.revokePublicURL", []string{"file"}, "Revokes public/external sharing access for a file")
-	cli.Register(cli.CallFilesSharedPublicURL, "files.sharedPublicURL", []string{"file"}, "Enables a file for public/external sharing")
-	cli.Register(cli.CallFilesUpload, "files.upload", []string{"channel", "filename"}, "Uploads or creates a file from local data")
-	cli.Register(cli.CallHelpIssuesList, "help.issues.list", []string{}, "List issues reported by the current user")
-	cli.Register(cli.CallMigrationExchange, "migration.exchange", []string{"users", "order"}, "For Enterprise Grid workspaces, map local user IDs to global user IDs")
-	cli.Register(cli.CallPaymentsBillingAddressesGet, "payments.billing.addresses.get", []string{}, "Gets the organization billing address")
-	cli.Register(cli.CallPaymentsBillingAddressesValidateAndSet, "payments.billing.addresses.validateAndSet", []string{"company_name", "street1", "street2", "city", "state", "zip", "country", "vat_id", "abn_id", "tax_id", "is_business", "is_checkout_v2", "is_vat_registered", "waiting_for_vat", "notes"}, "Validates and sets the organization billing address")
-	cli.Register(cli.CallPinsAdd, "pins.add", []string{"channel", "item_id"}, "Pins an item to a channel")
-	cli.Register(cli.CallPinsList, "pins.list", []string{"channel"}, "Lists items pinned to a channel")
-	cli.Register(cli.CallPinsRemove, "pins.remove", []string{"channel", "item_id"}, "Un-pins an item from a channel")
-	cli.Register(cli.CallReactionsAdd, "reactions.add", []string{"channel", "time", "name"}, "Adds a reaction to an item")
-	cli.Register(cli.CallReactionsGet, "reactions.get", []string{"channel", "time"}, "Gets reactions for an item")
-	cli.Register(cli.CallReactionsList, "reactions.list", []string{"user"}, "Lists reactions made by a user")
-	cli.Register(cli.CallReactionsRemove, "reactions.remove", []string{"channel", "time", "name"}, "Removes a reaction from an item")
-	cli.Register(cli.CallRtmEvents, "rtm.events", []string{}, "Prints the API events in real time")
-	cli.Register(cli.CallSignupCheckEmail, "signup.checkEmail", []string{"email"}, "Checks if an email address is valid")
-	cli.Register(cli.CallSignupConfirmEmail, "signup.confirmEmail", []string{"email"}, "Confirm an email address for signup")
-	cli.Register(cli.CallSearchAll, "search.all", []string{"query", "count", "page"}, "Searches for messages and files matching a query")
-	cli.Register(cli.CallSearchChannels, "search.channels", []string{"query", "count", "page"}, "Searches for channels matching a query")
-	cli.Register(cli.CallSearchFiles, "search.files", []string{"query", "count", "page"}, "Searches for files matching a query")
-	cli.Register(cli.CallSearchMessages, "search.messages", []string{"query", "count", "page"}, "Searches for messages matching a query")
-	cli.Register(cli.CallSearchModules, "search.modules", []string{"module", "query", "count", "page"}, "Searches for modules matching a query")
-	cli.Register(cli.CallSearchUsers, "search.users", []string{"user", "count"}, "Search users by name or email address")
-	cli.Register(cli.CallStarsAdd, "stars.add", []string{"channel", "item_id"}, "Adds a star to an item")
-	cli.Register(cli.CallStarsList, "stars.list", []string{"count", "page"}, "Lists stars for a user")
-	cli.Register(cli.CallStarsRemove, "stars.remove", []string{"channel", "item_id"}, "Removes a star from an item")
-	cli.Register(cli.CallTeamAccessLogs, "team.accessLogs", []string{"before", "count", "page"}, "Gets the access logs for the current team")
-	cli.Register(cli.CallTeamBillableInfo, "team.billableInfo", []string{"team_id", "user"}, "Gets billable users information for the current team")
-	cli.Register(cli.CallTeamBillingInfo, "team.billing.info", []string{}, "Reads a workspace's billing plan information")
-	cli.Register(cli.CallTeamChannelsInfo, "team.channels.info", []string{"team_id", "channels"}, "Retrieve a list of channels in a specific team")
-	cli.Register(cli.CallTeamChannelsMembership, "team.channels.membership", []string{"team_id", "channel", "users"}, "Retrieve membership information about a team")
-	cli.Register(cli.CallTeamInfo, "team.info", []string{"team"}, "Gets information about the current team")
-	cli.Register(cli.CallTeamIntegrationLogs, "team.integrationLogs", []string{"app_id", "change_type", "count", "page", "service_id", "team_id", "user"}, "Gets the integration logs for the current team")
-	cli.Register(cli.CallTeamListExternal, "team.listExternal", []string{}, "List external teams and their corresponding information")
-	cli.Register(cli.CallTeamPreferencesList, "team.preferences.list", []string{}, "Retrieve a list of a workspace's team preferences")
-	cli.Register(cli.CallTeamProfileGet, "team.profile.get", []string{}, "Retrieve a team's profile")
-	cli.Register(cli.CallUsersCounts, "users.counts", []string{}, "Count number of users in the team")
-	cli.Register(cli.CallUsersDeletePhoto, "users.deletePhoto", []string{}, "Delete the user avatar")
-	cli.Register(cli.CallUsersGetPresence, "users.getPresence", []string{"user"}, "Gets user presence information")
-	cli.Register(cli.CallUsersID, "users.id", []string{"user", "limit"}, "Gets user identifier from username")
-	cli.Register(cli.CallUsersIdentity, "users.identity", []string{}, "Get a user's identity")
-	cli.Register(cli.CallUsersInfo, "users.info", []string{"user"}, "Gets information about a user")
-	cli.Register(cli.CallUsersList, "users.list", []string{"limit", "cursor"}, "Lists all users in a Slack team")
-	cli.Register(cli.CallUsersLookupByEmail, "users.lookupByEmail", []string{"email"}, "Find a user with an email address")
-	cli.Register(cli.CallUsersPrefsGet, "users.prefs.get", []string{}, "Get user account preferences")
-	cli.Register(cli.CallUsersPrefsSet, "users.prefs.set", []string{"name", "value"}, "Set user account preferences")
-	cli.Register(cli.CallUsersPreparePhoto, "users.preparePhoto", []string{"image"}, "Upload a picture to use as the avatar")
-	cli.Register(cli.CallUsersProfileGet, "users.profile.get", []string{"user"}, "Retrieves a user's profile information")
-	cli.Register(cli.CallUsersProfileSet, "users.profile.set", []string{"name", "value"}, "Set the profile information for a user")
-	cli.Register(cli.CallUsersSetActive, "users.setActive", []string{}, "Marks a user as active")
-	cli.Register(cli.CallUsersSetAvatar, "users.setAvatar", []string{"image"}, "Upload a picture and set it as the avatar")
-	cli.Register(cli.CallUsersSetEmail, "users.setEmail", []string{"email"}, "Changes the email address without confirmation")
-	cli.Register(cli.CallUsersSetPhoto, "users.setPhoto", []string{"image_id"}, "Define which picture will be the avatar")
-	cli.Register(cli.CallUsersSetPresence, "users.setPresence", []string{"presence"}, "Manually sets user presence")
-	cli.Register(cli.CallUsersSetStatus, "users.setStatus", []string{"emoji", "text"}, "Set the status message and emoji")
-	cli.Register(cli.CallUsersSetUsername, "users.setUsername", []string{"username"}, "Changes the username without admin privileges")
-	cli.Register(cli.CallWorkflowsStepCompleted, "workflows.stepCompleted", []string{"workflow_step_execute_id"}, "Indicate that an app's step in a workflow completed execution")
-	cli.Register(cli.CallWorkflowsStepFailed, "workflows.stepFailed", []string{"workflow_step_execute_id", "error"}, "Indicate that an app's step in a workflow failed to execute")
-	cli.Register(cli.CallWorkflowsUpdateStep, "workflows.updateStep", []string{"workflow_step_edit_id", "step_image_url", "step_name"}, "Update the configuration for a workflow step")
-	cli.Register(cli.CallHelp, "help", []string{}, "Displays usage and program options")
-
-	flag.Usage = func() {
-		fmt.Println("Slack CLI")
-		fmt.Println("  http://cixtor.com/")
-		fmt.Println("  https://api.slack.com/")
-		fmt.Println("  https://github.com/cixtor/slackapi")
-		fmt.Println("  https://github.com/cixtor/slackcli")
-		fmt.Println()
-		fmt.Println("Description:")
-		fmt.Println("  Low level Slack API client with custom commands. Slack, the 'messaging app for")
-		fmt.Println("  teams' offers an API that has been used to build multiple projects around it,")
-		fmt.Println("  from bots to independent clients as well as integrations with other external")
-		fmt.Println("  services. This project aims to offer a low level experience for advanced users")
-		fmt.Println("  that want to either drop the web client or interact with the API for testing")
-		fmt.Println("  purpose.")
-		fmt.Println()
-		fmt.Println("Usage:")
-		flag.PrintDefaults()
-		cli.PrintCommands()
+	cli.flags = flags
+	cli.stdin = stdin
+	cli.stdout = stdout
+	cli.stderr = stderr
+
+	registerCommands(cli)
+
+	flags.Usage = func() {
+		fmt.Fprintln(stdout, "Slack CLI")
+		fmt.Fprintln(stdout, "  http://cixtor.com/")
+		fmt.Fprintln(stdout, "  https://api.slack.com/")
+		fmt.Fprintln(stdout, "  https://github.com/cixtor/slackapi")
+		fmt.Fprintln(stdout, "  https://github.com/cixtor/slackcli")
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Description:")
+		fmt.Fprintln(stdout, "  Low level Slack API client with custom commands. Slack, the 'messaging app for")
+		fmt.Fprintln(stdout, "  teams' offers an API that has been used to build multiple projects around it,")
+		fmt.Fprintln(stdout, "  from bots to independent clients as well as integrations with other external")
+		fmt.Fprintln(stdout, "  services. This project aims to offer a low level experience for advanced users")
+		fmt.Fprintln(stdout, "  that want to either drop the web client or interact with the API for testing")
+		fmt.Fprintln(stdout, "  purpose.")
+		fmt.Fprintln(stdout)
+		fmt.Fprintln(stdout, "Usage:")
+		flags.SetOutput(stdout)
+		flags.PrintDefaults()
+		flags.SetOutput(stderr)
+		cli.PrintCommands(cli.commands)
 	}
 
-	flag.Parse()
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return ExitSuccess
+	} else if err != nil {
+		return ExitUsage
+	}
 
-	cli.api.SetDebug(debugMode)
+	authenticate := cli.AutoAuthenticate
+
+	if offlineCommands[flags.Arg(0)] || (flags.NArg() > 1 && wantsHelp(flags.Args()[1:])) {
+		authenticate = cli.LoadProfile
+	}
+
+	if err := authenticate(*profileName); err != nil {
+		return cli.PrintError(fmt.Errorf("config; %s", err))
+	}
+
+	if err := cli.SetOutput(*outputFormat, *outputColumns); err != nil {
+		return cli.PrintError(UsageError("output; %s", err))
+	}
+
+	if err := cli.SetQuery(*outputQuery); err != nil {
+		return cli.PrintError(UsageError("%s", err))
+	}
+
+	if err := cli.SetTemplate(*outputTemplate, *outputTemplateFile); err != nil {
+		return cli.PrintError(UsageError("template; %s", err))
+	}
+
+	if err := cli.SetRecording(*recordDir, *replayDir); err != nil {
+		return cli.PrintError(UsageError("record; %s", err))
+	}
+
+	if err := cli.SetAPIURL(*apiURL); err != nil {
+		return cli.PrintError(UsageError("api-url; %s", err))
+	}
+
+	http.DefaultTransport = NewRetryTransport(http.DefaultTransport, *maxRetries, *retryTimeout)
+
+	cli.debug = *debugMode
+	cli.api.SetDebug(cli.debug)
+
+	return cli.Execute(flags.Args())
+}
 
-	os.Exit(cli.Execute(flag.Arg(0)))
+// registerCommands adds the commands of the program to an instance of CLI.
+func registerCommands(cli *CLI) {
+	cli.Register(cli.CallAPITest, "api.test", []Param{StringParam("error")}, "Checks API calling code")
+	cli.Register(cli.CallAPIGetFlannelHTTPURL, "api.getFlannelHttpUrl", []Param{}, "Gets the organization's canonical API endpoint")
+	cli.Register(cli.CallAppsConnectionsOpen, "apps.connections.open", []Param{}, "Generate a temporary Socket Mode WebSocket URL that your app can connect to in order to receive events and interactive payloads over")
+	cli.Register(cli.CallAppsEventAuthorizationsList, "apps.event.authorizations.list", Paginated(StringParam("event_context"), StringParam("cursor"), IntParam("limit", 100)), "Get a list of authorizations for the given event context. Each authorization represents an app installation that the event is visible to")
+	cli.Register(cli.CallAppsList, "apps.list", []Param{}, "Lists associated applications")
+	cli.Register(cli.CallAppsManifestCreate, "apps.manifest.create", []Param{TextParam("manifest")}, "Create an app from an app manifest")
+	cli.Register(cli.CallAppsManifestDelete, "apps.manifest.delete", []Param{StringParam("app_id")}, "Permanently deletes an app created through app manifests")
+	cli.Register(cli.CallAppsManifestExport, "apps.manifest.export", []Param{StringParam("app_id")}, "Export an app manifest from an existing app")
+	cli.Register(cli.CallAppsManifestUpdate, "apps.manifest.update", []Param{StringParam("app_id"), TextParam("manifest")}, "Update an app from an app manifest")
+	cli.Register(cli.CallAppsManifestValidate, "apps.manifest.validate", []Param{TextParam("manifest"), StringParam("app_id")}, "Validate an app manifest")
+	cli.Register(cli.CallAuthLogin, "auth.login", []Param{SecretParam("token"), SecretParam("cookie")}, "Verifies a token and saves it in the credential store")
+	cli.Register(cli.CallAuthLogout, "auth.logout", []Param{}, "Deletes the saved credentials of the active profile")
+	cli.Register(cli.CallAuthRevoke, "auth.revoke", []Param{StringParam("test")}, "Revokes a token")
+	cli.Register(cli.CallAuthTeamsList, "auth.teams.list", Paginated(StringParam("cursor"), BoolParam("include_icon"), IntParam("limit", 100)), "List the workspaces a token can access")
+	cli.Register(cli.CallAuthTest, "auth.test", []Param{}, "Checks authentication and identity")
+	cli.Register(cli.CallBatch, "batch", []Param{StringParam("file"), IntParam("concurrency", 1)}, "Executes the commands from a file or the standard input, one per line or as JSON objects with method and args")
+	cli.Register(cli.CallBotsInfo, "bots.info", []Param{StringParam("bot")}, "Gets information about a bot user")
+	cli.Register(cli.CallCacheClear, "cache.clear", []Param{}, "Deletes the cached list of channels and users")
+	cli.Register(cli.CallCacheRefresh, "cache.refresh", []Param{}, "Downloads the list of channels and users used to resolve names")
+	cli.Register(cli.CallCall, "call", []Param{StringParam("method"), RestParam("args"), BoolParam("get")}, "Sends a request to any method of the web API service with key=value, key:=json, key=@file or @file arguments")
+	cli.Register(cli.CallCompletion, "completion", []Param{EnumParam("shell", "bash", "zsh", "fish", "powershell"), EnumParam("names", "channels", "users")}, "Prints the script that completes the commands in bash, zsh, fish or PowerShell")
+	cli.Register(cli.CallChatDelete, "chat.delete", []Param{ChannelParam("channel"), MessageParam("time")}, "Deletes a message")
+	cli.Register(cli.CallChatDeleteAttachment, "chat.deleteAttachment", []Param{ChannelParam("channel"), MessageParam("time"), IntParam("attachment", 1)}, "Deletes a message attachment")
+	cli.Register(cli.CallChatDeleteScheduledMessage, "chat.deleteScheduledMessage", []Param{ChannelParam("channel"), StringParam("scheduled_message_id")}, "Deletes a pending scheduled message")
+	cli.Register(cli.CallChatDM, "chat.dm", MessageOptions(UserParam("user"), TextParam("text"), TextParam("blocks"), ThreadParam("thread-ts")), "Sends a direct message to a user")
+	cli.Register(cli.CallChatGetPermalink, "chat.getPermalink", []Param{ChannelParam("channel"), MessageParam("time")}, "Retrieves the URL of a message")
+	cli.Register(cli.CallChatMeMessage, "chat.meMessage", MessageOptions(ChannelParam("channel"), TextParam("text"), ThreadParam("thread-ts")), "Share a me message into a channel")
+	cli.Register(cli.CallChatMPDM, "chat.mpdm", MessageOptions(UserListParam("users"), TextParam("text"), TextParam("blocks"), ThreadParam("thread-ts")), "Sends a message to a group of users in a multi-person direct message")
+	cli.Register(cli.CallChatPostAttachment, "chat.postAttachment", []Param{ChannelParam("channel"), TextParam("json")}, "Sends an attachment to a channel")
+	cli.Register(cli.CallChatPostEphemeral, "chat.postEphemeral", []Param{ChannelParam("channel"), UserParam("user"), TextParam("text"), TextParam("blocks"), ThreadParam("thread-ts"), BoolParam("link-names"), EnumParam("parse", "none", "full")}, "Sends a message that only one user in the channel can see")
+	cli.Register(cli.CallChatPostMessage, "chat.postMessage", MessageOptions(ChannelParam("channel"), TextParam("text"), TextParam("blocks"), ThreadParam("thread-ts")), "Sends a message to a channel")
+	cli.Register(cli.CallChatReply, "chat.reply", MessageOptions(StringParam("permalink"), TextParam("text"), TextParam("blocks")), "Replies in the thread of a message, given its URL")
+	cli.Register(cli.CallChatRobotMessage, "chat.robotMessage", MessageOptions(ChannelParam("channel"), TextParam("text"), TextParam("blocks"), ThreadParam("thread-ts")), "Sends a message to a channel as a robot")
+	cli.Register(cli.CallChatScheduleMessage, "chat.scheduleMessage", MessageOptions(ChannelParam("channel"), StringParam("post_at"), TextParam("text"), TextParam("blocks"), ThreadParam("thread-ts")), "Schedules a message to be sent to a channel")
+	cli.Register(cli.CallChatScheduledMessagesList, "chat.scheduledMessages.list", Paginated(ChannelParam("channel"), StringParam("oldest"), StringParam("latest"), StringParam("cursor"), IntParam("limit", 100)), "Lists the pending scheduled messages")
+	cli.Register(cli.CallChatUpdate, "chat.update", []Param{ChannelParam("channel"), MessageParam("time"), TextParam("text"), TextParam("blocks")}, "Updates a message")
+	cli.Register(cli.CallClientCounts, "client.counts", []Param{}, "List mentions in different conversations")
+	cli.Register(cli.CallClientShouldReload, "client.shouldReload", []Param{StringParam("team_ids"), IntParam("version_ts", 1), IntParam("build_version_ts", 1), IntParam("config_version_ts", 1)}, "Determine if the Slack client must reload or not")
+	cli.Register(cli.CallConversationsAcceptSharedInvite, "conversations.acceptSharedInvite", []Param{StringParam("channel_name"), StringParam("channel_id"), BoolParam("free_trial_accepted"), StringParam("invite_id"), BoolParam("is_private"), StringParam("team_id")}, "Accepts an invitation to a Slack Connect channel")
+	cli.Register(cli.CallConversationsApproveSharedInvite, "conversations.approveSharedInvite", []Param{StringParam("invite_id"), StringParam("target_team")}, "Approves an invitation to a Slack Connect channel")
+	cli.Register(cli.CallConversationsArchive, "conversations.archive", []Param{ChannelParam("room")}, "Archives a conversation")
+	cli.Register(cli.CallConversationsClose, "conversations.close", []Param{ChannelParam("room")}, "Closes a direct message or multi-person direct message")
+	cli.Register(cli.CallConversationsCreate, "conversations.create", []Param{StringParam("name"), BoolParam("is_private"), StringParam("team_id")}, "Initiates a public or private channel-based conversation")
+	cli.Register(cli.CallConversationsDeclineSharedInvite, "conversations.declineSharedInvite", []Param{StringParam("invite_id"), StringParam("target_team")}, "Declines a Slack Connect channel invite")
+	cli.Register(cli.CallConversationsDelete, "conversations.delete", []Param{ChannelParam("channel")}, "Delete a public or private channel")
+	cli.Register(cli.CallConversationsGenericInfo, "conversations.genericInfo", []Param{ChannelListParam("channels")}, "Retrieve information about various channels")
+	cli.Register(cli.CallConversationsHistory, "conversations.history", []Param{ChannelParam("room"), MessageParam("time")}, "Fetches a conversation's history of messages and events")
+	cli.Register(cli.CallConversationsID, "conversations.id", []Param{StringParam("room"), IntParam("count", 100), IntParam("page", 1)}, "Prints the conversation ID fo the specified room")
+	cli.Register(cli.CallConversationsInfo, "conversations.info", []Param{ChannelParam("room")}, "Retrieve information about a conversation")
+	cli.Register(cli.CallConversationsInvite, "conversations.invite", []Param{ChannelParam("room"), UserParam("user")}, "Invites users to a channel")
+	cli.Register(cli.CallConversationsInviteShared, "conversations.inviteShared", []Param{ChannelParam("channel"), ListParam("emails"), BoolParam("external_limited"), UserListParam("user_ids")}, "Sends an invitation to a Slack Connect channel")
+	cli.Register(cli.CallConversationsJoin, "conversations.join", []Param{ChannelParam("room")}, "Joins an existing conversation")
+	cli.Register(cli.CallConversationsKick, "conversations.kick", []Param{ChannelParam("room"), UserParam("user")}, "Removes a user from a conversation")
+	cli.Register(cli.CallConversationsLeave, "conversations.leave", []Param{ChannelParam("room")}, "Leaves a conversation")
+	cli.Register(cli.CallConversationsList, "conversations.list", []Param{}, "Lists all channels in a Slack team")
+	cli.Register(cli.CallConversationsListConnectInvites, "conversations.listConnectInvites", Paginated(IntParam("count", 100), StringParam("cursor"), StringParam("team_id")), "Lists shared channel invites that have been generated or received but have not been approved by all parties")
+	cli.Register(cli.CallConversationsMark, "conversations.mark", []Param{ChannelParam("room"), MessageParam("time")}, "Sets the read cursor in a channel")
+	cli.Register(cli.CallConversationsMembers, "conversations.members", Paginated(ChannelParam("channel"), StringParam("cursor"), IntParam("limit", 100)), "Retrieve members of a conversation")
+	cli.Register(cli.CallConversationsOpen, "conversations.open", []Param{ChannelParam("channel"), BoolParam("prevent_creation"), BoolParam("return_im"), UserListParam("users")}, "Opens or resumes a direct message or multi-person direct message")
+	cli.Register(cli.CallConversationsRename, "conversations.rename", []Param{ChannelParam("room"), StringParam("name")}, "Renames a conversation")
+	cli.Register(cli.CallConversationsReplies, "conversations.replies", Paginated(ChannelParam("channel"), ThreadParam("ts"), StringParam("cursor"), BoolParam("inclusive"), StringParam("latest"), IntParam("limit", 1000), StringParam("oldest")), "Retrieve a thread of messages posted to a conversation")
+	cli.Register(cli.CallConversationsSetPurpose, "conversations.setPurpose", []Param{ChannelParam("room"), StringParam("purpose")}, "Sets the purpose for a conversation")
+	cli.Register(cli.CallConversationsSetTopic, "conversations.setTopic", []Param{ChannelParam("room"), StringParam("topic")}, "Sets the topic for a conversation")
+	cli.Register(cli.CallConversationsSuggestions, "conversations.suggestions", []Param{}, "List Slack suggestions to join conversations")
+	cli.Register(cli.CallConversationsUnarchive, "conversations.unarchive", []Param{ChannelParam("room")}, "Reverses conversation archival")
+	cli.Register(cli.CallDndEndDnd, "dnd.endDnd", []Param{}, "Ends the current user's \"Do Not Disturb\" session immediately")
+	cli.Register(cli.CallDndEndSnooze, "dnd.endSnooze", []Param{}, "Ends the current user's snooze mode immediately")
+	cli.Register(cli.CallDndInfo, "dnd.info", []Param{UserParam("user")}, "Retrieves a user's current \"Do Not Disturb\" status")
+	cli.Register(cli.CallDndSetSnooze, "dnd.setSnooze", []Param{IntParam("minutes", 60)}, "Ends the current user's snooze mode immediately")
+	cli.Register(cli.CallDndTeamInfo, "dnd.teamInfo", []Param{UserListParam("users")}, "Retrieves the \"Do Not Disturb\" status for users on a team")
+	cli.Register(cli.CallEmojiList, "emoji.list", []Param{}, "Lists custom emoji for a team")
+	cli.Register(cli.CallEventlogHistory, "eventlog.history", []Param{StringParam("time")}, "Lists all the events since the specified time")
+	cli.Register(cli.CallFilesCommentsAdd, "files.comments.add", []Param{StringParam("file"), TextParam("text")}, "Add a comment to an existing file")
+	cli.Register(cli.CallFilesCommentsDelete, "files.comments.delete", []Param{StringParam("file"), StringParam("fcid")}, "Deletes an existing comment on a file")
+	cli.Register(cli.CallFilesCommentsEdit, "files.comments.edit", []Param{StringParam("file"), StringParam("fcid"), TextParam("text")}, "Edit an existing file comment")
+	cli.Register(cli.CallFilesDelete, "files.delete", []Param{StringParam("file")}, "Deletes a file and associated comments")
+	cli.Register(cli.CallFilesInfo, "files.info", []Param{StringParam("file"), IntParam("count", 1000), IntParam("page", 1)}, "Gets information about a team file")
+	cli.Register(cli.CallFilesList, "files.list", Paginated(IntParam("count", 1000), IntParam("page", 0)), "Lists and filters team files")
+	cli.Register(cli.CallFilesListAfterTime, "files.listAfterTime", Paginated(StringParam("time"), IntParam("count", 1000), IntParam("page", 0)), "Lists and filters team files after this timestamp (inclusive)")
+	cli.Register(cli.CallFilesListBeforeTime, "files.listBeforeTime", Paginated(StringParam("time"), IntParam("count", 1000), IntParam("page", 0)), "Lists and filters team files before this timestamp (inclusive)")
+	cli.Register(cli.CallFilesListByChannel, "files.listByChannel", Paginated(ChannelParam("channel"), IntParam("count", 1000), IntParam("page", 0)), "Lists and filters team files in a specific channel")
+	cli.Register(cli.CallFilesListByType, "files.listByType", Paginated(EnumParam("type", "all", "posts", "snippets", "images", "gdocs", "zips", "pdfs"), IntParam("count", 1000), IntParam("page", 0)), "Lists and filters team files by type: all, posts, snippets, images, gdocs, zips, pdfs")
+	cli.Register(cli.CallFilesListByUser, "files.listByUser", Paginated(UserParam("user"), IntParam("count", 1000), IntParam("page", 0)), "Lists and filters team files created by a single user")
+	cli.Register(cli.CallFilesRevokePublicURL, "files.revokePublicURL", []Param{StringParam("file")}, "Revokes public/external sharing access for a file")
+	cli.Register(cli.CallFilesSharedPublicURL, "files.sharedPublicURL", []Param{StringParam("file")}, "Enables a file for public/external sharing")
+	cli.Register(cli.CallFilesUpload, "files.upload", []Param{ChannelParam("channel"), StringParam("filename")}, "Uploads or creates a file from local data")
+	cli.Register(cli.CallHelpIssuesList, "help.issues.list", []Param{}, "List issues reported by the current user")
+	cli.Register(cli.CallMan, "man", []Param{StringParam("dir")}, "Prints the man page of the program, or writes one page per command into a folder")
+	cli.Register(cli.CallMigrationExchange, "migration.exchange", []Param{UserListParam("users"), BoolParam("order")}, "For Enterprise Grid workspaces, map local user IDs to global user IDs")
+	cli.Register(cli.CallPaymentsBillingAddressesGet, "payments.billing.addresses.get", []Param{}, "Gets the organization billing address")
+	cli.Register(cli.CallPaymentsBillingAddressesValidateAndSet, "payments.billing.addresses.validateAndSet", []Param{StringParam("company_name"), StringParam("street1"), StringParam("street2"), StringParam("city"), StringParam("state"), StringParam("zip"), StringParam("country"), StringParam("vat_id"), StringParam("abn_id"), StringParam("tax_id"), BoolParam("is_business"), BoolParam("is_checkout_v2"), BoolParam("is_vat_registered"), BoolParam("waiting_for_vat"), StringParam("notes")}, "Validates and sets the organization billing address")
+	cli.Register(cli.CallPinsAdd, "pins.add", []Param{ChannelParam("channel"), MessageParam("item_id")}, "Pins an item to a channel")
+	cli.Register(cli.CallPinsList, "pins.list", []Param{ChannelParam("channel")}, "Lists items pinned to a channel")
+	cli.Register(cli.CallPinsRemove, "pins.remove", []Param{ChannelParam("channel"), MessageParam("item_id")}, "Un-pins an item from a channel")
+	cli.Register(cli.CallProfileAdd, "profile.add", []Param{StringParam("name"), SecretParam("token"), SecretParam("cookie"), StringParam("default_channel"), StringParam("robot_name"), StringParam("robot_image"), StringParam("api_url")}, "Creates or replaces a profile in the configuration file")
+	cli.Register(cli.CallProfileList, "profile.list", []Param{}, "Lists the profiles in the configuration file")
+	cli.Register(cli.CallProfileRemove, "profile.remove", []Param{StringParam("name")}, "Deletes a profile from the configuration file")
+	cli.Register(cli.CallProfileUse, "profile.use", []Param{StringParam("name")}, "Sets the profile used by default")
+	cli.Register(cli.CallReactionsAdd, "reactions.add", []Param{ChannelParam("channel"), MessageParam("time"), StringParam("name")}, "Adds a reaction to an item")
+	cli.Register(cli.CallReactionsGet, "reactions.get", []Param{ChannelParam("channel"), MessageParam("time")}, "Gets reactions for an item")
+	cli.Register(cli.CallReactionsList, "reactions.list", []Param{UserParam("user")}, "Lists reactions made by a user")
+	cli.Register(cli.CallReactionsRemove, "reactions.remove", []Param{ChannelParam("channel"), MessageParam("time"), StringParam("name")}, "Removes a reaction from an item")
+	cli.Register(cli.CallRtmEvents, "rtm.events", []Param{}, "Prints the API events in real time")
+	cli.Register(cli.CallSignupCheckEmail, "signup.checkEmail", []Param{StringParam("email")}, "Checks if an email address is valid")
+	cli.Register(cli.CallSignupConfirmEmail, "signup.confirmEmail", []Param{StringParam("email")}, "Confirm an email address for signup")
+	cli.Register(cli.CallSearchAll, "search.all", Paginated(StringParam("query"), IntParam("count", 100), IntParam("page", 1)), "Searches for messages and files matching a query")
+	cli.Register(cli.CallSearchChannels, "search.channels", Paginated(StringParam("query"), IntParam("count", 100), IntParam("page", 1)), "Searches for channels matching a query")
+	cli.Register(cli.CallSearchFiles, "search.files", Paginated(StringParam("query"), IntParam("count", 100), IntParam("page", 1)), "Searches for files matching a query")
+	cli.Register(cli.CallSearchMessages, "search.messages", Paginated(StringParam("query"), IntParam("count", 100), IntParam("page", 1)), "Searches for messages matching a query")
+	cli.Register(cli.CallSearchModules, "search.modules", Paginated(StringParam("module"), StringParam("query"), IntParam("count", 100), IntParam("page", 1)), "Searches for modules matching a query")
+	cli.Register(cli.CallSearchUsers, "search.users", []Param{StringParam("user"), IntParam("count", 100)}, "Search users by name or email address")
+	cli.Register(cli.CallStarsAdd, "stars.add", []Param{ChannelParam("channel"), MessageParam("item_id")}, "Adds a star to an item")
+	cli.Register(cli.CallStarsList, "stars.list", Paginated(IntParam("count", 1000), IntParam("page", 1)), "Lists stars for a user")
+	cli.Register(cli.CallStarsRemove, "stars.remove", []Param{ChannelParam("channel"), MessageParam("item_id")}, "Removes a star from an item")
+	cli.Register(cli.CallTeamAccessLogs, "team.accessLogs", Paginated(StringParam("before"), IntParam("count", 1000), IntParam("page", 1)), "Gets the access logs for the current team")
+	cli.Register(cli.CallTeamBillableInfo, "team.billableInfo", []Param{StringParam("team_id"), UserParam("user")}, "Gets billable users information for the current team")
+	cli.Register(cli.CallTeamBillingInfo, "team.billing.info", []Param{}, "Reads a workspace's billing plan information")
+	cli.Register(cli.CallTeamChannelsInfo, "team.channels.info", []Param{StringParam("team_id"), ChannelListParam("channels")}, "Retrieve a list of channels in a specific team")
+	cli.Register(cli.CallTeamChannelsMembership, "team.channels.membership", []Param{StringParam("team_id"), ChannelParam("channel"), UserListParam("users")}, "Retrieve membership information about a team")
+	cli.Register(cli.CallTeamInfo, "team.info", []Param{StringParam("team")}, "Gets information about the current team")
+	cli.Register(cli.CallTeamIntegrationLogs, "team.integrationLogs", []Param{StringParam("app_id"), StringParam("change_type"), StringParam("count"), StringParam("page"), StringParam("service_id"), StringParam("team_id"), UserParam("user")}, "Gets the integration logs for the current team")
+	cli.Register(cli.CallTeamListExternal, "team.listExternal", []Param{}, "List external teams and their corresponding information")
+	cli.Register(cli.CallTeamPreferencesList, "team.preferences.list", []Param{}, "Retrieve a list of a workspace's team preferences")
+	cli.Register(cli.CallTeamProfileGet, "team.profile.get", []Param{}, "Retrieve a team's profile")
+	cli.Register(cli.CallUsersCounts, "users.counts", []Param{}, "Count number of users in the team")
+	cli.Register(cli.CallUsersDeletePhoto, "users.deletePhoto", []Param{}, "Delete the user avatar")
+	cli.Register(cli.CallUsersGetPresence, "users.getPresence", []Param{UserParam("user")}, "Gets user presence information")
+	cli.Register(cli.CallUsersID, "users.id", []Param{StringParam("user"), IntParam("limit", 100)}, "Gets user identifier from username")
+	cli.Register(cli.CallUsersIdentity, "users.identity", []Param{}, "Get a user's identity")
+	cli.Register(cli.CallUsersInfo, "users.info", []Param{UserParam("user")}, "Gets information about a user")
+	cli.Register(cli.CallUsersList, "users.list", Paginated(IntParam("limit", 100), StringParam("cursor")), "Lists all users in a Slack team")
+	cli.Register(cli.CallUsersLookupByEmail, "users.lookupByEmail", []Param{StringParam("email")}, "Find a user with an email address")
+	cli.Register(cli.CallUsersPrefsGet, "users.prefs.get", []Param{}, "Get user account preferences")
+	cli.Register(cli.CallUsersPrefsSet, "users.prefs.set", []Param{StringParam("name"), StringParam("value")}, "Set user account preferences")
+	cli.Register(cli.CallUsersPreparePhoto, "users.preparePhoto", []Param{StringParam("image")}, "Upload a picture to use as the avatar")
+	cli.Register(cli.CallUsersProfileGet, "users.profile.get", []Param{UserParam("user")}, "Retrieves a user's profile information")
+	cli.Register(cli.CallUsersProfileSet, "users.profile.set", []Param{StringParam("name"), StringParam("value")}, "Set the profile information for a user")
+	cli.Register(cli.CallUsersSetActive, "users.setActive", []Param{}, "Marks a user as active")
+	cli.Register(cli.CallUsersSetAvatar, "users.setAvatar", []Param{StringParam("image")}, "Upload a picture and set it as the avatar")
+	cli.Register(cli.CallUsersSetEmail, "users.setEmail", []Param{StringParam("email")}, "Changes the email address without confirmation")
+	cli.Register(cli.CallUsersSetPhoto, "users.setPhoto", []Param{StringParam("image_id")}, "Define which picture will be the avatar")
+	cli.Register(cli.CallUsersSetPresence, "users.setPresence", []Param{EnumParam("presence", "auto", "away")}, "Manually sets user presence")
+	cli.Register(cli.CallUsersSetStatus, "users.setStatus", []Param{StringParam("emoji"), StringParam("text")}, "Set the status message and emoji")
+	cli.Register(cli.CallUsersSetUsername, "users.setUsername", []Param{StringParam("username")}, "Changes the username without admin privileges")
+	cli.Register(cli.CallWorkflowsStepCompleted, "workflows.stepCompleted", []Param{StringParam("workflow_step_execute_id")}, "Indicate that an app's step in a workflow completed execution")
+	cli.Register(cli.CallWorkflowsStepFailed, "workflows.stepFailed", []Param{StringParam("workflow_step_execute_id"), StringParam("error")}, "Indicate that an app's step in a workflow failed to execute")
+	cli.Register(cli.CallWorkflowsUpdateStep, "workflows.updateStep", []Param{StringParam("workflow_step_edit_id"), StringParam("step_image_url"), StringParam("step_name")}, "Update the configuration for a workflow step")
+	cli.Register(cli.CallShell, "shell", []Param{}, "Starts an interactive session with history and tab completion of commands, channels and users; \"-\" cannot read stdin inside it, use @path")
+	cli.Register(cli.CallHelp, "help", []Param{StringParam("command")}, "Displays usage and program options, or the parameters, scopes and an example of a command")
 }
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cixtor/slackapi"
+)
+
+// MessageOptions appends the options of the commands that post a message:
+//
+//	--reply-broadcast  also shows the reply in the thread in the channel
+//	--unfurl-links     shows a preview of the links to text-based content
+//	--no-unfurl        shows no preview of the links nor the media
+//	--mrkdwn=false     sends the text without formatting
+//	--link-names       links the @names and #channels in the text
+//	--parse            treatment of the text, none or full
+func MessageOptions(params ...Param) []Param {
+	return append(params,
+		BoolParam("reply-broadcast"),
+		BoolParam("unfurl-links"),
+		BoolParam("no-unfurl"),
+		Param{Name: "mrkdwn", Type: TypeBool, Default: "true"},
+		BoolParam("link-names"),
+		EnumParam("parse", "none", "full"),
+	)
+}
+
+// sendMessage sends a message with the chat.postMessage, chat.meMessage or
+// chat.update action. Messages with blocks or with the options of
+// MessageOptions are sent with Invoke because the library only supports
+// text and legacy attachments.
+func (cli *CLI) sendMessage(method string, data slackapi.MessageArgs) int {
+	raw, custom, err := cli.messageRequest(data)
+
+	if err != nil {
+		return cli.PrintError(UsageError("%s; %s", cli.command, err))
+	}
+
+	if !custom {
+		switch method {
+		case "chat.update":
+			return cli.PrintJSON(cli.api.ChatUpdate(data))
+		case "chat.meMessage":
+			return cli.PrintJSON(cli.api.ChatMeMessage(data))
+		}
+
+		return cli.PrintJSON(cli.api.ChatPostMessage(data))
+	}
+
+	return cli.invokeMessage(method, raw)
+}
+
+// messageRequest returns the arguments of a message for Invoke, with the
+// blocks and the options of the command. The blocks are validated before
+// the message is sent. It reports whether the message uses blocks or
+// options that the library does not support.
+func (cli *CLI) messageRequest(data slackapi.MessageArgs) (RawArgs, bool, error) {
+	raw := messageArgs(data)
+
+	custom, err := cli.messageOptions(raw)
+
+	if err != nil {
+		return raw, false, err
+	}
+
+	if input := cli.String("blocks"); input != "" {
+		blocks, err := ParseBlocks(input)
+
+		if err != nil {
+			return raw, false, err
+		}
+
+		raw.JSON["blocks"] = blocks
+		custom = true
+	}
+
+	return raw, custom, nil
+}
+
+// invokeMessage sends the arguments of a message with Invoke and prints the
+// response.
+func (cli *CLI) invokeMessage(method string, raw RawArgs) int {
+	res, err := cli.Invoke(http.MethodPost, method, raw)
+
+	if err != nil {
+		return cli.PrintError(fmt.Errorf("%s; %s", cli.command, err))
+	}
+
+	return cli.PrintJSON(res)
+}
+
+// messageArgs converts the arguments of a message into the arguments of a
+// request sent with Invoke.
+func messageArgs(data slackapi.MessageArgs) RawArgs {
+	raw := RawArgs{
+		Values: map[string]string{"channel": data.Channel},
+		JSON:   map[string]json.RawMessage{},
+	}
+
+	fields := map[string]string{
+		"text":       data.Text,
+		"ts":         data.Ts,
+		"username":   data.Username,
+		"icon_emoji": data.IconEmoji,
+		"icon_url":   data.IconURL,
+	}
+
+	for key, value := range fields {
+		if value != "" {
+			raw.Values[key] = value
+		}
+	}
+
+	if data.AsUser {
+		raw.JSON["as_user"] = json.RawMessage("true")
+	}
+
+	return raw
+}
+
+// messageOptions adds the thread-ts parameter and the options of
+// MessageOptions to the arguments of a message. It reports whether any
+// option differs from its default, because the commands without these
+// options leave them unset.
+func (cli *CLI) messageOptions(raw RawArgs) (bool, error) {
+	custom := false
+
+	enable := func(key string, value string) {
+		raw.JSON[key] = json.RawMessage(value)
+		custom = true
+	}
+
+	if ts := cli.String("thread-ts"); ts != "" {
+		raw.Values["thread_ts"] = ts
+		custom = true
+	}
+
+	if cli.Bool("reply-broadcast") {
+		if cli.String("thread-ts") == "" {
+			return false, errors.New("reply-broadcast requires thread-ts")
+		}
+
+		enable("reply_broadcast", "true")
+	}
+
+	if cli.Bool("unfurl-links") && cli.Bool("no-unfurl") {
+		return false, errors.New("unfurl-links and no-unfurl cannot be used together")
+	}
+
+	if cli.Bool("unfurl-links") {
+		enable("unfurl_links", "true")
+	}
+
+	if cli.Bool("no-unfurl") {
+		enable("unfurl_links", "false")
+		enable("unfurl_media", "false")
+	}
+
+	if cli.params["mrkdwn"] == "false" {
+		enable("mrkdwn", "false")
+	}
+
+	if cli.Bool("link-names") {
+		enable("link_names", "true")
+	}
+
+	if parse := cli.String("parse"); parse != "" {
+		raw.Values["parse"] = parse
+		custom = true
+	}
+
+	return custom, nil
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// OutputFormats lists the formats supported by the -output flag.
+var OutputFormats = []string{"json", "json-compact", "ndjson", "yaml", "csv", "tsv", "table"}
+
+// tableColumns defines the columns printed by the csv, tsv and table formats
+// for some commands when the user does not choose them with -columns. Other
+// commands print every scalar field of the first item.
+var tableColumns = map[string][]string{
+	"apps.event.authorizations.list": {"team_id", "user_id", "is_bot"},
+	"auth.teams.list":                {"id", "name", "domain"},
+	"chat.scheduledMessages.list":    {"id", "channel_id", "post_at", "text"},
+	"conversations.history":          {"ts", "user", "text"},
+	"conversations.list":             {"id", "name", "num_members"},
+	"conversations.replies":          {"ts", "user", "text"},
+	"files.list":                     {"id", "name", "filetype", "size", "user"},
+	"files.listAfterTime":            {"id", "name", "filetype", "size", "user"},
+	"files.listBeforeTime":           {"id", "name", "filetype", "size", "user"},
+	"files.listByChannel":            {"id", "name", "filetype", "size", "user"},
+	"files.listByType":               {"id", "name", "filetype", "size", "user"},
+	"files.listByUser":               {"id", "name", "filetype", "size", "user"},
+	"profile.list":                   {"name", "channel", "token"},
+	"search.messages":                {"ts", "channel.name", "username", "text"},
+	"team.accessLogs":                {"user_id", "username", "date_last", "ip"},
+	"team.integrationLogs":           {"date", "user_name", "service_type", "change_type"},
+	"users.list":                     {"id", "name", "real_name", "tz"},
+}
+
+// SetOutput configures how PrintJSON renders successful responses. Columns is
+// a comma-separated list of fields, nested fields use a dot as the separator.
+func (cli *CLI) SetOutput(format string, columns string) error {
+	for _, name := range OutputFormats {
+		if name == format {
+			cli.output = format
+
+			if columns != "" {
+				cli.columns = strings.Split(columns, ",")
+			}
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown output format %q, use one of %s", format, strings.Join(OutputFormats, ", "))
+}
+
+// render prints a JSON document in the output format chosen by the user.
+func (cli *CLI) render(w io.Writer, data []byte) error {
+	var v interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&v); err != nil {
+		return err
+	}
+
+	switch cli.output {
+	case "json-compact":
+		return json.NewEncoder(w).Encode(v)
+
+	case "ndjson":
+		encoder := json.NewEncoder(w)
+
+		for _, item := range listItems(v) {
+			if err := encoder.Encode(item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+
+	case "yaml":
+		var buf bytes.Buffer
+		writeYAML(&buf, v, 0)
+		_, err := w.Write(buf.Bytes())
+		return err
+
+	case "csv", "tsv":
+		rows := listItems(v)
+		columns := cli.tableColumns(rows)
+		writer := csv.NewWriter(w)
+
+		if cli.output == "tsv" {
+			writer.Comma = '\t'
+		}
+
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if err := writer.Write(tableRow(row, columns)); err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+
+	case "table":
+		rows := listItems(v)
+		columns := cli.tableColumns(rows)
+		writer := tabwriter.NewWriter(w, 0, 8, 2, '\x20', 0)
+
+		fmt.Fprintln(writer, strings.ToUpper(strings.Join(columns, "\t")))
+
+		for _, row := range rows {
+			cells := tableRow(row, columns)
+
+			for i, cell := range cells {
+				cells[i] = strings.NewReplacer("\t", "\x20", "\n", "\x20").Replace(cell)
+			}
+
+			fmt.Fprintln(writer, strings.Join(cells, "\t"))
+		}
+
+		return writer.Flush()
+	}
+
+	out, err := json.MarshalIndent(v, "", "\x20\x20")
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", out)
+	return err
+}
+
+// listItems returns the items of every list in a response, which is usually
+// one list like the "members" in users.list. If the response does not contain
+// a list, it returns the only object in the response, like the "user" in
+// users.info, or the response itself.
+func listItems(v interface{}) []interface{} {
+	var items []interface{}
+
+	object, ok := v.(map[string]interface{})
+
+	if !ok {
+		if list, ok := v.([]interface{}); ok {
+			return list
+		}
+
+		return []interface{}{v}
+	}
+
+	mergePage(map[string]interface{}{}, object, -1, func(item interface{}) {
+		items = append(items, item)
+	})
+
+	if items != nil {
+		return items
+	}
+
+	delete(object, "ok")
+	delete(object, "warning")
+	delete(object, "response_metadata")
+
+	if len(object) == 1 {
+		for _, value := range object {
+			if nested, ok := value.(map[string]interface{}); ok {
+				return []interface{}{nested}
+			}
+		}
+	}
+
+	return []interface{}{object}
+}
+
+// tableColumns returns the columns selected by the user, the default columns
+// of the command, or the scalar fields of the first row.
+func (cli *CLI) tableColumns(rows []interface{}) []string {
+	if len(cli.columns) > 0 {
+		return cli.columns
+	}
+
+	if columns, ok := tableColumns[cli.command]; ok {
+		return columns
+	}
+
+	var columns []string
+
+	if len(rows) > 0 {
+		if object, ok := rows[0].(map[string]interface{}); ok {
+			for key, value := range object {
+				switch value.(type) {
+				case map[string]interface{}, []interface{}:
+					continue
+				}
+
+				columns = append(columns, key)
+			}
+		}
+	}
+
+	if columns == nil {
+		return []string{"value"}
+	}
+
+	sort.Strings(columns)
+
+	return columns
+}
+
+// tableRow returns the value of each column, nested objects and lists are
+// printed as compact JSON.
+func tableRow(row interface{}, columns []string) []string {
+	cells := make([]string, len(columns))
+
+	for i, column := range columns {
+		value := row
+
+		if _, ok := row.(map[string]interface{}); ok || column != "value" {
+			value = lookupField(row, column)
+		}
+
+		cells[i] = scalarText(value)
+	}
+
+	return cells
+}
+
+// lookupField follows a dotted path like "profile.email" into an object.
+func lookupField(v interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		object, ok := v.(map[string]interface{})
+
+		if !ok {
+			return nil
+		}
+
+		v = object[key]
+	}
+
+	return v
+}
+
+func scalarText(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		if value {
+			return "true"
+		}
+		return "false"
+	}
+
+	out, _ := json.Marshal(v)
+
+	return string(out)
+}
+
+// writeYAML encodes a decoded JSON document as YAML, keys are sorted and
+// strings are quoted whenever a plain scalar would be ambiguous.
+func writeYAML(buf *bytes.Buffer, v interface{}, indent int) {
+	prefix := strings.Repeat("\x20\x20", indent)
+
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			buf.WriteString(prefix + "{}\n")
+			return
+		}
+
+		keys := make([]string, 0, len(value))
+
+		for key := range value {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			buf.WriteString(prefix + yamlString(key) + ":")
+			writeYAMLValue(buf, value[key], indent)
+		}
+
+	case []interface{}:
+		if len(value) == 0 {
+			buf.WriteString(prefix + "[]\n")
+			return
+		}
+
+		for _, item := range value {
+			if object, ok := item.(map[string]interface{}); ok && len(object) > 0 {
+				// write the object one level deeper and move the first key
+				// next to the dash, like "- id: U123".
+				var nested bytes.Buffer
+				writeYAML(&nested, object, indent+1)
+				buf.WriteString(prefix + "- ")
+				buf.Write(nested.Bytes()[len(prefix)+2:])
+				continue
+			}
+
+			buf.WriteString(prefix + "-")
+			writeYAMLValue(buf, item, indent)
+		}
+
+	default:
+		buf.WriteString(prefix + yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLValue writes the value of a key or list item, which was already
+// written without a trailing space, at the given indentation level.
+func writeYAMLValue(buf *bytes.Buffer, v interface{}, indent int) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(value) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+
+		buf.WriteString("\n")
+		writeYAML(buf, value, indent+1)
+
+	case []interface{}:
+		if len(value) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+
+		buf.WriteString("\n")
+		writeYAML(buf, value, indent+1)
+
+	default:
+		buf.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlScalar(v interface{}) string {
+	if text, ok := v.(string); ok {
+		return yamlString(text)
+	}
+
+	if v == nil {
+		return "null"
+	}
+
+	return scalarText(v)
+}
+
+func yamlString(text string) string {
+	plain := text != "" &&
+		strings.TrimSpace(text) == text &&
+		!strings.ContainsAny(text, ":#{}[],&*!|>'\"%@`\n\t\\") &&
+		!strings.ContainsAny(text[:1], "-?0123456789.+") &&
+		!isYAMLKeyword(text)
+
+	if plain {
+		return text
+	}
+
+	out, _ := json.Marshal(text)
+
+	return string(out)
+}
+
+func isYAMLKeyword(text string) bool {
+	switch strings.ToLower(text) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~", "y", "n":
+		return true
+	}
+
+	return false
+}
+
+// printRendered writes a response in the chosen output format, or falls back
+// to the indented JSON if the document cannot be rendered.
+func (cli *CLI) printRendered(out []byte) int {
+	if err := cli.render(cli.stdout, out); err != nil {
+		return cli.PrintError(fmt.Errorf("output; %s", err))
+	}
+
+	return 0
+}
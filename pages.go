@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Paginated adds the options to follow the pagination of a list command.
+//
+//	--all        fetches every page until the results are exhausted
+//	--max-pages  stops after fetching this many pages
+//	--max-items  stops after collecting this many items
+//	--stream     prints each item as a JSON line as soon as it arrives
+//
+// The last three options imply --all. With --stream, the query and template
+// chosen by the user are applied to each item instead of the whole list.
+func Paginated(params ...Param) []Param {
+	return append(params,
+		BoolParam("all"),
+		IntParam("max-pages", 0),
+		IntParam("max-items", 0),
+		BoolParam("stream"),
+	)
+}
+
+// PrintCursorPages prints the results of a list command that paginates with
+// a cursor. With --all, it follows response_metadata.next_cursor until the
+// web API service returns an empty cursor.
+func (cli *CLI) PrintCursorPages(fetch func(cursor string) interface{}) int {
+	cursor := cli.String("cursor")
+
+	return cli.printPages(func(prev map[string]interface{}) interface{} {
+		if prev != nil {
+			if cursor = nextCursor(prev); cursor == "" {
+				return nil
+			}
+		}
+
+		return fetch(cursor)
+	})
+}
+
+// PrintNumberedPages prints the results of a list command that paginates with
+// a page number. With --all, it increments the page number until it reaches
+// the number of pages reported in the paging object of the response.
+func (cli *CLI) PrintNumberedPages(fetch func(page int) interface{}) int {
+	number := cli.Number("page")
+
+	if cli.followPages() && number < 1 {
+		number = 1
+	}
+
+	return cli.printPages(func(prev map[string]interface{}) interface{} {
+		if prev != nil {
+			if number >= totalPages(prev) {
+				return nil
+			}
+
+			number++
+		}
+
+		return fetch(number)
+	})
+}
+
+// printPages calls next with the previous page, or nil for the first one,
+// until it returns nil or the limits are reached. Pages are merged into one
+// response, or streamed item by item if the user prefers so.
+func (cli *CLI) printPages(next func(prev map[string]interface{}) interface{}) int {
+	if !cli.followPages() {
+		return cli.PrintJSON(next(nil))
+	}
+
+	var prev map[string]interface{}
+	var emit func(item interface{})
+
+	items := 0
+	failed := 0
+	maxPages := cli.Number("max-pages")
+	maxItems := cli.Number("max-items")
+	merged := map[string]interface{}{}
+
+	if cli.Bool("stream") {
+		switch cli.output {
+		case "", "json", "json-compact", "ndjson":
+		default:
+			return cli.PrintError(UsageError("--stream prints JSON lines, it cannot be used with -output %s", cli.output))
+		}
+
+		emit = func(item interface{}) {
+			if code := cli.printItem(item); code != 0 && failed == 0 {
+				failed = code
+			}
+		}
+	}
+
+	for pages := 0; maxPages <= 0 || pages < maxPages; pages++ {
+		v := next(prev)
+
+		if v == nil {
+			break
+		}
+
+		page, err := decodePage(v)
+
+		if err != nil {
+			return cli.PrintJSON(v)
+		}
+
+		if res := ResponseOf(v); !res.Ok && res.Error != "" {
+			return cli.PrintJSON(v)
+		}
+
+		budget := -1
+
+		if maxItems > 0 {
+			budget = maxItems - items
+		}
+
+		items += mergePage(merged, page, budget, emit)
+
+		if maxItems > 0 && items >= maxItems {
+			break
+		}
+
+		prev = page
+	}
+
+	if emit != nil {
+		return failed
+	}
+
+	return cli.PrintJSON(merged)
+}
+
+// followPages returns true if the user asked for more than the first page,
+// either with --all or with one of the options that imply it.
+func (cli *CLI) followPages() bool {
+	return cli.Bool("all") || cli.Bool("stream") || cli.Number("max-pages") > 0 || cli.Number("max-items") > 0
+}
+
+// printItem prints one item of a list as a JSON line, or with the template
+// chosen by the user. If there is a query, the values that it selects from the
+// item are printed one by one instead, like jq does.
+func (cli *CLI) printItem(item interface{}) int {
+	values := []interface{}{item}
+
+	if cli.query != nil {
+		values = cli.query.Eval(item)
+	}
+
+	for _, value := range values {
+		out, err := json.Marshal(value)
+
+		if err != nil {
+			return cli.PrintError(fmt.Errorf("json.encode; %s", err))
+		}
+
+		if cli.template != nil {
+			if code := cli.printTemplate(out); code != 0 {
+				return code
+			}
+
+			continue
+		}
+
+		fmt.Fprintf(cli.stdout, "%s\n", out)
+	}
+
+	return 0
+}
+
+// decodePage converts a response into a generic object, numbers are kept
+// as they are to avoid losing precision in timestamps and identifiers.
+func decodePage(v interface{}) (map[string]interface{}, error) {
+	var page map[string]interface{}
+
+	out, err := json.Marshal(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&page); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// pageMetadata are the fields that describe a page rather than hold its
+// items. Their lists, like response_metadata.warnings, are not items.
+var pageMetadata = map[string]bool{"response_metadata": true, "paging": true}
+
+// mergePage appends the lists in src to the lists in dst, descending into
+// nested objects like the "messages" object in the search results. Any other
+// value in src, and the metadata of the page, replaces the one in dst. If
+// emit is not nil, the list items are passed to it instead and the metadata
+// is left out. A negative budget means there is no limit to the
+// number of items. It returns the number of items that were added.
+func mergePage(dst map[string]interface{}, src map[string]interface{}, budget int, emit func(interface{})) int {
+	added := 0
+	keys := make([]string, 0, len(src))
+
+	for key := range src {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if pageMetadata[key] {
+			if emit == nil {
+				dst[key] = src[key]
+			}
+
+			continue
+		}
+
+		remaining := -1
+
+		if budget >= 0 {
+			remaining = budget - added
+		}
+
+		switch value := src[key].(type) {
+		case []interface{}:
+			if remaining >= 0 && len(value) > remaining {
+				value = value[:remaining]
+			}
+
+			if emit != nil {
+				for _, item := range value {
+					emit(item)
+				}
+			} else {
+				list, _ := dst[key].([]interface{})
+				dst[key] = append(list, value...)
+			}
+
+			added += len(value)
+
+		case map[string]interface{}:
+			nested, ok := dst[key].(map[string]interface{})
+
+			if !ok {
+				nested = map[string]interface{}{}
+				dst[key] = nested
+			}
+
+			added += mergePage(nested, value, remaining, emit)
+
+		default:
+			dst[key] = value
+		}
+	}
+
+	return added
+}
+
+// nextCursor returns the value of response_metadata.next_cursor, if any.
+func nextCursor(page map[string]interface{}) string {
+	meta, _ := page["response_metadata"].(map[string]interface{})
+	cursor, _ := meta["next_cursor"].(string)
+	return cursor
+}
+
+// totalPages returns the number of pages reported by the paging object of a
+// response. Search results have one paging object for each module, in which
+// case the biggest number wins.
+func totalPages(page map[string]interface{}) int {
+	total := 0
+
+	if paging, ok := page["paging"].(map[string]interface{}); ok {
+		if number, ok := paging["pages"].(json.Number); ok {
+			if n, err := number.Int64(); err == nil && int(n) > total {
+				total = int(n)
+			}
+		}
+	}
+
+	for key, value := range page {
+		if nested, ok := value.(map[string]interface{}); ok && key != "paging" {
+			if n := totalPages(nested); n > total {
+				total = n
+			}
+		}
+	}
+
+	return total
+}
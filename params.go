@@ -0,0 +1,313 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParamType defines the kind of value a command parameter accepts.
+type ParamType int
+
+const (
+	// TypeString accepts any text.
+	TypeString ParamType = iota
+	// TypeInt accepts a base-10 integer.
+	TypeInt
+	// TypeBool accepts true or false; the named flag can omit the value.
+	TypeBool
+	// TypeList accepts a comma-separated list of values.
+	TypeList
+	// TypeRest accepts every remaining positional argument.
+	TypeRest
+)
+
+// restSeparator joins the arguments collected by a TypeRest parameter.
+const restSeparator = "\x1f"
+
+// ResolveKind defines how the value of a parameter is converted into an ID.
+type ResolveKind int
+
+const (
+	// ResolveNone leaves the value as it is.
+	ResolveNone ResolveKind = iota
+	// ResolveChannel accepts #name, @user for a direct message, or an ID.
+	ResolveChannel
+	// ResolveUser accepts @name, an email address, or an ID.
+	ResolveUser
+	// ResolveMessage accepts the timestamp of a message, or its URL, which
+	// also sets the channel of the command.
+	ResolveMessage
+	// ResolveThread accepts the timestamp of the parent message of a thread,
+	// or the URL of any message in the thread, which also sets the channel.
+	ResolveThread
+)
+
+// Param defines a named argument accepted by a command.
+type Param struct {
+	Name    string
+	Type    ParamType
+	Default string
+	Values  []string
+	Resolve ResolveKind
+	Input   bool
+	Secret  bool
+}
+
+// StringParam returns a parameter that accepts any text.
+func StringParam(name string) Param {
+	return Param{Name: name, Type: TypeString}
+}
+
+// TextParam returns a parameter that accepts any text, or "-" to read the
+// text from stdin and "@path" to read it from a file.
+func TextParam(name string) Param {
+	return Param{Name: name, Type: TypeString, Input: true}
+}
+
+// SecretParam returns a parameter like TextParam for a token or a password.
+// The value is better read from stdin or a file, because an argument is saved
+// in the shell history and is visible in the list of processes.
+func SecretParam(name string) Param {
+	return Param{Name: name, Type: TypeString, Input: true, Secret: true}
+}
+
+// IntParam returns a parameter that accepts an integer.
+func IntParam(name string, initial int) Param {
+	return Param{Name: name, Type: TypeInt, Default: strconv.Itoa(initial)}
+}
+
+// BoolParam returns a parameter that accepts a boolean.
+func BoolParam(name string) Param {
+	return Param{Name: name, Type: TypeBool, Default: "false"}
+}
+
+// ListParam returns a parameter that accepts a comma-separated list.
+func ListParam(name string) Param {
+	return Param{Name: name, Type: TypeList}
+}
+
+// EnumParam returns a parameter that accepts one or more comma-separated
+// values from a fixed set of options.
+func EnumParam(name string, values ...string) Param {
+	return Param{Name: name, Type: TypeList, Values: values}
+}
+
+// RestParam returns a parameter that collects every positional argument from
+// its position onwards, so the parameters declared after it can only be given
+// by name.
+func RestParam(name string) Param {
+	return Param{Name: name, Type: TypeRest}
+}
+
+// ChannelParam returns a parameter that accepts a channel name or ID.
+func ChannelParam(name string) Param {
+	return Param{Name: name, Type: TypeString, Resolve: ResolveChannel}
+}
+
+// ChannelListParam returns a parameter that accepts a comma-separated list
+// of channel names or IDs.
+func ChannelListParam(name string) Param {
+	return Param{Name: name, Type: TypeList, Resolve: ResolveChannel}
+}
+
+// UserParam returns a parameter that accepts a username, email or user ID.
+func UserParam(name string) Param {
+	return Param{Name: name, Type: TypeString, Resolve: ResolveUser}
+}
+
+// UserListParam returns a parameter that accepts a comma-separated list of
+// usernames, emails or user IDs.
+func UserListParam(name string) Param {
+	return Param{Name: name, Type: TypeList, Resolve: ResolveUser}
+}
+
+// MessageParam returns a parameter that accepts the timestamp of a message,
+// or the URL of the message.
+func MessageParam(name string) Param {
+	return Param{Name: name, Type: TypeString, Resolve: ResolveMessage}
+}
+
+// ThreadParam returns a parameter that accepts the timestamp of the parent
+// message of a thread, or the URL of a message in the thread.
+func ThreadParam(name string) Param {
+	return Param{Name: name, Type: TypeString, Resolve: ResolveThread}
+}
+
+// Validate checks if the user input is acceptable for the parameter.
+func (p Param) Validate(input string) error {
+	switch p.Type {
+	case TypeInt:
+		if _, err := strconv.Atoi(input); err != nil {
+			return fmt.Errorf("%s expects an integer, got %q", p.Name, input)
+		}
+	case TypeBool:
+		if _, err := strconv.ParseBool(input); err != nil {
+			return fmt.Errorf("%s expects true or false, got %q", p.Name, input)
+		}
+	}
+
+	if len(p.Values) == 0 {
+		return nil
+	}
+
+	for _, item := range strings.Split(input, ",") {
+		if !p.accepts(item) {
+			return fmt.Errorf("%s expects one of %s, got %q", p.Name, strings.Join(p.Values, ", "), item)
+		}
+	}
+
+	return nil
+}
+
+func (p Param) accepts(value string) bool {
+	for _, option := range p.Values {
+		if option == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// paramValue implements flag.Value to collect named parameters.
+type paramValue struct {
+	param Param
+	value string
+	isSet bool
+}
+
+func (v *paramValue) String() string {
+	return v.value
+}
+
+func (v *paramValue) Set(input string) error {
+	if err := v.param.Validate(input); err != nil {
+		return err
+	}
+
+	v.value = input
+	v.isSet = true
+
+	return nil
+}
+
+func (v *paramValue) IsBoolFlag() bool {
+	return v.param.Type == TypeBool
+}
+
+// ParseParams reads the command arguments into a map of parameter values.
+// Parameters can be given by name, like --channel=general or --limit 200, or
+// by position, following the order in which the command declared them, for
+// backward compatibility. Positional arguments skip the parameters that were
+// already given by name, and an empty positional argument keeps the default
+// value but still occupies its slot. Arguments that start with a dash but do
+// not name a parameter, like "-5 degrees", are positional.
+func ParseParams(name string, params []Param, args []string) (map[string]string, error) {
+	var positional []string
+
+	values := make([]*paramValue, len(params))
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	flags.SetOutput(io.Discard)
+
+	for i, param := range params {
+		values[i] = &paramValue{param: param}
+		flags.Var(values[i], param.Name, "")
+	}
+
+	for len(args) > 0 {
+		if args[0] == "--" {
+			positional = append(positional, args[1:]...)
+			break
+		}
+
+		param, ok := flagParam(params, args[0])
+
+		if !ok {
+			positional = append(positional, args[0])
+			args = args[1:]
+			continue
+		}
+
+		size := 1
+
+		if param.Type != TypeBool && !strings.Contains(args[0], "=") && len(args) > 1 {
+			size = 2
+		}
+
+		if err := flags.Parse(args[:size]); err != nil {
+			return nil, err
+		}
+
+		args = args[size:]
+	}
+
+	for _, value := range values {
+		if value.isSet {
+			continue
+		}
+
+		if len(positional) == 0 {
+			break
+		}
+
+		if value.param.Type == TypeRest {
+			value.isSet = true
+			value.value = strings.Join(positional, restSeparator)
+			positional = nil
+			break
+		}
+
+		input := positional[0]
+		positional = positional[1:]
+
+		if input == "" {
+			continue
+		}
+
+		if err := value.Set(input); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(positional) > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", positional[0])
+	}
+
+	out := make(map[string]string, len(values))
+
+	for _, value := range values {
+		if value.isSet {
+			out[value.param.Name] = value.value
+		} else {
+			out[value.param.Name] = value.param.Default
+		}
+	}
+
+	return out, nil
+}
+
+// flagParam returns the parameter named by an argument like -name, --name or
+// --name=value, if the command declares it.
+func flagParam(params []Param, arg string) (Param, bool) {
+	if !strings.HasPrefix(arg, "-") {
+		return Param{}, false
+	}
+
+	name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+
+	if i := strings.Index(name, "="); i >= 0 {
+		name = name[:i]
+	}
+
+	for _, param := range params {
+		if param.Name == name {
+			return param, true
+		}
+	}
+
+	return Param{}, false
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// permalinkPattern matches the path of the URL of a message, where the time
+// of the message follows the letter p without the dot.
+var permalinkPattern = regexp.MustCompile(`^/archives/([A-Z0-9]+)/p([0-9]+)([0-9]{6})$`)
+
+// Permalink is the location of a message taken from its URL, like the ones
+// copied with "Copy link" in the Slack clients:
+//
+//	https://example.slack.com/archives/C0123456789/p1650000000123456
+//
+// Replies in a thread also have the time of the parent message in the
+// thread_ts query parameter.
+type Permalink struct {
+	Channel  string
+	Time     string
+	ThreadTS string
+}
+
+// ParsePermalink extracts the channel and the time of a message from its URL.
+func ParsePermalink(input string) (Permalink, error) {
+	link, err := url.Parse(strings.TrimSpace(input))
+
+	if err != nil || link.Host == "" {
+		return Permalink{}, fmt.Errorf("%q is not a message URL", input)
+	}
+
+	match := permalinkPattern.FindStringSubmatch(strings.TrimSuffix(link.Path, "/"))
+
+	if match == nil {
+		return Permalink{}, fmt.Errorf("%q is not a message URL, expected https://<workspace>.slack.com/archives/<channel>/p<time>", input)
+	}
+
+	return Permalink{
+		Channel:  match[1],
+		Time:     match[2] + "." + match[3],
+		ThreadTS: link.Query().Get("thread_ts"),
+	}, nil
+}
+
+// Thread returns the time of the message that starts the thread of the
+// message, which is the message itself if it is not a reply.
+func (p Permalink) Thread() string {
+	if p.ThreadTS != "" {
+		return p.ThreadTS
+	}
+
+	return p.Time
+}
+
+// expandPermalinks replaces the URL of a message given as the first
+// positional argument of a command, in place of the channel, with the
+// channel and the time of the message given by name, so the URL fills both
+// parameters and the next argument fills the one that follows them, like
+// "reactions.add <url> thumbsup". Only the commands that act on a message
+// expand it; the URL is not taken as a thread to reply in, which is what
+// chat.reply and --thread-ts are for.
+func expandPermalinks(params []Param, args []string) []string {
+	channel, target := permalinkParams(params)
+
+	if channel == nil || target == nil || target.Resolve != ResolveMessage || params[0].Name != channel.Name {
+		return args
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			return args
+		}
+
+		if param, ok := flagParam(params, arg); ok {
+			if param.Type != TypeBool && !strings.Contains(arg, "=") {
+				i++
+			}
+
+			continue
+		}
+
+		if !strings.Contains(arg, "://") {
+			return args
+		}
+
+		link, err := ParsePermalink(arg)
+
+		if err != nil {
+			return args
+		}
+
+		expanded := append([]string{}, args[:i]...)
+		expanded = append(expanded, "-"+channel.Name+"="+link.Channel, "-"+target.Name+"="+link.timeFor(*target))
+
+		return append(expanded, args[i+1:]...)
+	}
+
+	return args
+}
+
+// expandPermalinkParams replaces the URL of a message given by name with the
+// time of the message, in the parameters of a command that expect one, or
+// with the channel of the message in the channel parameter. The other one of
+// the pair is taken from the URL too, unless it was given or it is a thread.
+func (cli *CLI) expandPermalinkParams(command Command) error {
+	channel, target := permalinkParams(command.Params)
+
+	if channel != nil && strings.Contains(cli.params[channel.Name], "://") {
+		link, err := ParsePermalink(cli.params[channel.Name])
+
+		if err != nil {
+			return fmt.Errorf("%s: %s", channel.Name, err)
+		}
+
+		cli.params[channel.Name] = link.Channel
+
+		if target != nil && target.Resolve == ResolveMessage && cli.params[target.Name] == "" {
+			cli.params[target.Name] = link.timeFor(*target)
+		}
+	}
+
+	for _, param := range command.Params {
+		value := cli.params[param.Name]
+
+		if (param.Resolve != ResolveMessage && param.Resolve != ResolveThread) || !strings.Contains(value, "://") {
+			continue
+		}
+
+		link, err := ParsePermalink(value)
+
+		if err != nil {
+			return fmt.Errorf("%s: %s", param.Name, err)
+		}
+
+		cli.params[param.Name] = link.timeFor(param)
+
+		if channel != nil && cli.params[channel.Name] == "" {
+			cli.params[channel.Name] = link.Channel
+		}
+	}
+
+	return nil
+}
+
+// timeFor returns the time of the message for a message parameter, or the
+// time of the thread for a thread parameter.
+func (p Permalink) timeFor(param Param) string {
+	if param.Resolve == ResolveThread {
+		return p.Thread()
+	}
+
+	return p.Time
+}
+
+// permalinkParams returns the channel parameter and the first message or
+// thread parameter of a command, if it has them.
+func permalinkParams(params []Param) (*Param, *Param) {
+	var channel, target *Param
+
+	for i := range params {
+		if params[i].Resolve == ResolveChannel && params[i].Type == TypeString && channel == nil {
+			channel = &params[i]
+		}
+
+		if (params[i].Resolve == ResolveMessage || params[i].Resolve == ResolveThread) && target == nil {
+			target = &params[i]
+		}
+	}
+
+	return channel, target
+}
@@ -0,0 +1,646 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query is a filter applied to the responses before they are printed. The
+// syntax is a subset of jq, with some JSONPath aliases:
+//
+//	.channels[].name                     every channel name
+//	.members[0]                          the first item of a list
+//	.members[-1]                         the last item of a list
+//	$.channels[*].id                     JSONPath wildcard, same as []
+//	.user.profile.*                      every value of an object
+//	.["is_ok"]                           quoted keys
+//	.messages[] | select(.user == "U1")  filter items with a predicate
+//	.channels[] | select(.num_members > 10 and .is_private == false) | .name
+//
+// A query always produces a list with the values it selects, even if there is
+// one or none, so the type of the output does not depend on the response.
+type Query struct {
+	stages []queryStage
+}
+
+type queryStage interface {
+	eval(v interface{}) []interface{}
+}
+
+// queryPath selects values following a list of keys, indexes and wildcards.
+type queryPath struct {
+	steps []queryStep
+}
+
+type queryStep struct {
+	key   string
+	index int
+	kind  int
+}
+
+const (
+	stepKey = iota
+	stepIndex
+	stepAll
+)
+
+// querySelect keeps the values for which the condition is true.
+type querySelect struct {
+	cond queryCond
+}
+
+// queryCond is a list of comparisons joined by "or", each one a list of
+// comparisons joined by "and".
+type queryCond struct {
+	any [][]queryComparison
+}
+
+type queryComparison struct {
+	left  queryOperand
+	op    string
+	right queryOperand
+}
+
+type queryOperand struct {
+	path    *queryPath
+	literal interface{}
+}
+
+// SetQuery compiles the filter applied to every successful response.
+func (cli *CLI) SetQuery(expr string) error {
+	if expr == "" {
+		return nil
+	}
+
+	query, err := ParseQuery(expr)
+
+	if err != nil {
+		return err
+	}
+
+	cli.query = query
+
+	return nil
+}
+
+// filter applies the query to a JSON document and encodes the result.
+func (cli *CLI) filter(data []byte) ([]byte, error) {
+	var v interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(cli.query.Eval(v), "", "\x20\x20")
+}
+
+// ParseQuery compiles a query expression.
+func ParseQuery(expr string) (*Query, error) {
+	p := &queryParser{input: expr}
+	query := &Query{}
+
+	for {
+		p.skipSpaces()
+
+		stage, err := p.parseStage()
+
+		if err != nil {
+			return nil, err
+		}
+
+		query.stages = append(query.stages, stage)
+
+		p.skipSpaces()
+
+		if p.done() {
+			return query, nil
+		}
+
+		if !p.consume("|") {
+			return nil, p.errorf("expected |")
+		}
+	}
+}
+
+// Eval runs the query against a decoded JSON document and returns the list of
+// values it selects.
+func (q *Query) Eval(v interface{}) []interface{} {
+	values := []interface{}{v}
+
+	for _, stage := range q.stages {
+		var next []interface{}
+
+		for _, value := range values {
+			next = append(next, stage.eval(value)...)
+		}
+
+		values = next
+	}
+
+	if values == nil {
+		return []interface{}{}
+	}
+
+	return values
+}
+
+func (q *queryPath) eval(v interface{}) []interface{} {
+	values := []interface{}{v}
+
+	for _, step := range q.steps {
+		var next []interface{}
+
+		for _, value := range values {
+			switch step.kind {
+			case stepKey:
+				if object, ok := value.(map[string]interface{}); ok {
+					next = append(next, object[step.key])
+				} else {
+					next = append(next, nil)
+				}
+
+			case stepIndex:
+				list, _ := value.([]interface{})
+				index := step.index
+
+				if index < 0 {
+					index += len(list)
+				}
+
+				if index >= 0 && index < len(list) {
+					next = append(next, list[index])
+				} else {
+					next = append(next, nil)
+				}
+
+			case stepAll:
+				switch container := value.(type) {
+				case []interface{}:
+					next = append(next, container...)
+				case map[string]interface{}:
+					for _, key := range sortedKeys(container) {
+						next = append(next, container[key])
+					}
+				}
+			}
+		}
+
+		values = next
+	}
+
+	return values
+}
+
+func (q *querySelect) eval(v interface{}) []interface{} {
+	for _, all := range q.cond.any {
+		matches := true
+
+		for _, comparison := range all {
+			if !comparison.eval(v) {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return []interface{}{v}
+		}
+	}
+
+	return nil
+}
+
+func (c queryComparison) eval(v interface{}) bool {
+	left := c.left.eval(v)
+
+	if c.op == "" {
+		return left != nil && left != false
+	}
+
+	right := c.right.eval(v)
+
+	switch c.op {
+	case "==":
+		return queryEqual(left, right)
+	case "!=":
+		return !queryEqual(left, right)
+	}
+
+	order, ok := queryCompare(left, right)
+
+	if !ok {
+		return false
+	}
+
+	switch c.op {
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	}
+
+	return false
+}
+
+func (o queryOperand) eval(v interface{}) interface{} {
+	if o.path == nil {
+		return o.literal
+	}
+
+	if values := o.path.eval(v); len(values) > 0 {
+		return values[0]
+	}
+
+	return nil
+}
+
+func queryEqual(a interface{}, b interface{}) bool {
+	if order, ok := queryCompare(a, b); ok {
+		return order == 0
+	}
+
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+
+	return string(x) == string(y)
+}
+
+// queryCompare orders two numbers or two strings.
+func queryCompare(a interface{}, b interface{}) (int, bool) {
+	if x, ok := queryNumber(a); ok {
+		if y, ok := queryNumber(b); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+
+			return 0, true
+		}
+	}
+
+	x, ok := a.(string)
+
+	if !ok {
+		return 0, false
+	}
+
+	y, ok := b.(string)
+
+	if !ok {
+		return 0, false
+	}
+
+	return strings.Compare(x, y), true
+}
+
+func queryNumber(v interface{}) (float64, bool) {
+	switch number := v.(type) {
+	case json.Number:
+		n, err := number.Float64()
+		return n, err == nil
+	case float64:
+		return number, true
+	}
+
+	return 0, false
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+
+	for key := range object {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// queryParser is a recursive descent parser for the query expressions.
+type queryParser struct {
+	input string
+	pos   int
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("query: %s at position %d", fmt.Sprintf(format, args...), p.pos+1)
+}
+
+func (p *queryParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *queryParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+
+	return p.input[p.pos]
+}
+
+func (p *queryParser) skipSpaces() {
+	for !p.done() && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *queryParser) consume(token string) bool {
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+
+	return false
+}
+
+// consumeWord consumes a keyword only if it is not the prefix of a longer
+// identifier, so "or" does not match the beginning of "order".
+func (p *queryParser) consumeWord(word string) bool {
+	if !strings.HasPrefix(p.input[p.pos:], word) {
+		return false
+	}
+
+	end := p.pos + len(word)
+
+	if end < len(p.input) && isIdentChar(p.input[end]) {
+		return false
+	}
+
+	p.pos = end
+
+	return true
+}
+
+func (p *queryParser) parseStage() (queryStage, error) {
+	if p.consumeWord("select") {
+		p.skipSpaces()
+
+		if !p.consume("(") {
+			return nil, p.errorf("expected ( after select")
+		}
+
+		cond, err := p.parseCond()
+
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipSpaces()
+
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+
+		return &querySelect{cond: cond}, nil
+	}
+
+	return p.parsePath()
+}
+
+func (p *queryParser) parsePath() (*queryPath, error) {
+	path := &queryPath{}
+
+	if !p.consume("$") && p.peek() != '.' {
+		return nil, p.errorf("expected a path starting with . or $")
+	}
+
+	for !p.done() {
+		switch p.peek() {
+		case '.':
+			p.pos++
+
+			if p.consume("*") {
+				path.steps = append(path.steps, queryStep{kind: stepAll})
+			} else if isIdentChar(p.peek()) {
+				path.steps = append(path.steps, queryStep{kind: stepKey, key: p.parseIdent()})
+			} else if p.peek() != '[' && !p.done() && !unicode.IsSpace(rune(p.peek())) && p.peek() != '|' && p.peek() != ')' {
+				return nil, p.errorf("unexpected %q", p.peek())
+			}
+
+		case '[':
+			step, err := p.parseBracket()
+
+			if err != nil {
+				return nil, err
+			}
+
+			path.steps = append(path.steps, step)
+
+		default:
+			return path, nil
+		}
+	}
+
+	return path, nil
+}
+
+func (p *queryParser) parseBracket() (queryStep, error) {
+	p.pos++
+	p.skipSpaces()
+
+	if p.consume("]") {
+		return queryStep{kind: stepAll}, nil
+	}
+
+	if p.consume("*") {
+		p.skipSpaces()
+
+		if !p.consume("]") {
+			return queryStep{}, p.errorf("expected ]")
+		}
+
+		return queryStep{kind: stepAll}, nil
+	}
+
+	if p.peek() == '"' || p.peek() == '\'' {
+		key, err := p.parseString()
+
+		if err != nil {
+			return queryStep{}, err
+		}
+
+		p.skipSpaces()
+
+		if !p.consume("]") {
+			return queryStep{}, p.errorf("expected ]")
+		}
+
+		return queryStep{kind: stepKey, key: key}, nil
+	}
+
+	start := p.pos
+
+	if p.peek() == '-' {
+		p.pos++
+	}
+
+	for !p.done() && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+
+	index, err := strconv.Atoi(p.input[start:p.pos])
+
+	if err != nil {
+		return queryStep{}, p.errorf("expected an index")
+	}
+
+	p.skipSpaces()
+
+	if !p.consume("]") {
+		return queryStep{}, p.errorf("expected ]")
+	}
+
+	return queryStep{kind: stepIndex, index: index}, nil
+}
+
+func (p *queryParser) parseIdent() string {
+	start := p.pos
+
+	for !p.done() && isIdentChar(p.peek()) {
+		p.pos++
+	}
+
+	return p.input[start:p.pos]
+}
+
+func (p *queryParser) parseString() (string, error) {
+	quote := p.peek()
+	start := p.pos
+	p.pos++
+
+	for !p.done() && p.peek() != quote {
+		if p.peek() == '\\' {
+			p.pos++
+		}
+
+		p.pos++
+	}
+
+	if !p.consume(string(quote)) {
+		return "", p.errorf("unterminated string")
+	}
+
+	if quote == '\'' {
+		return strings.ReplaceAll(p.input[start+1:p.pos-1], `\'`, `'`), nil
+	}
+
+	var text string
+
+	if err := json.Unmarshal([]byte(p.input[start:p.pos]), &text); err != nil {
+		return "", p.errorf("invalid string")
+	}
+
+	return text, nil
+}
+
+func (p *queryParser) parseCond() (queryCond, error) {
+	var cond queryCond
+	var all []queryComparison
+
+	for {
+		p.skipSpaces()
+
+		comparison, err := p.parseComparison()
+
+		if err != nil {
+			return cond, err
+		}
+
+		all = append(all, comparison)
+
+		p.skipSpaces()
+
+		if p.consumeWord("and") {
+			continue
+		}
+
+		cond.any = append(cond.any, all)
+		all = nil
+
+		if !p.consumeWord("or") {
+			return cond, nil
+		}
+	}
+}
+
+func (p *queryParser) parseComparison() (queryComparison, error) {
+	var comparison queryComparison
+	var err error
+
+	if comparison.left, err = p.parseOperand(); err != nil {
+		return comparison, err
+	}
+
+	p.skipSpaces()
+
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consume(op) {
+			comparison.op = op
+			break
+		}
+	}
+
+	if comparison.op == "" {
+		return comparison, nil
+	}
+
+	p.skipSpaces()
+
+	comparison.right, err = p.parseOperand()
+
+	return comparison, err
+}
+
+func (p *queryParser) parseOperand() (queryOperand, error) {
+	switch {
+	case p.peek() == '.' || p.peek() == '$':
+		path, err := p.parsePath()
+		return queryOperand{path: path}, err
+
+	case p.peek() == '"' || p.peek() == '\'':
+		text, err := p.parseString()
+		return queryOperand{literal: text}, err
+
+	case p.consumeWord("true"):
+		return queryOperand{literal: true}, nil
+
+	case p.consumeWord("false"):
+		return queryOperand{literal: false}, nil
+
+	case p.consumeWord("null"):
+		return queryOperand{literal: nil}, nil
+	}
+
+	start := p.pos
+
+	for !p.done() && strings.IndexByte("+-0123456789.eE", p.peek()) >= 0 {
+		p.pos++
+	}
+
+	number := json.Number(p.input[start:p.pos])
+
+	if _, err := number.Float64(); err != nil || start == p.pos {
+		return queryOperand{}, p.errorf("expected a path, string, number, boolean or null")
+	}
+
+	return queryOperand{literal: number}, nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// secretPattern matches the tokens and session cookies of the web API service,
+// like xoxp-123-456 or xoxd-abc%2Fdef, which are redacted from the recordings.
+var secretPattern = regexp.MustCompile(`xox[a-z]-[A-Za-z0-9%._\-]+`)
+
+// redactedHeaders are replaced entirely in the recordings.
+var redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Interaction is a request sent to the web API service and its response, as
+// saved in a recording. Bodies that are not valid UTF-8, like file uploads,
+// are encoded in base64.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+	used     bool
+}
+
+// RecordedRequest defines the request of an interaction.
+type RecordedRequest struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+	BodyBase64 bool        `json:"body_base64,omitempty"`
+}
+
+// RecordedResponse defines the response of an interaction.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+	BodyBase64 bool        `json:"body_base64,omitempty"`
+}
+
+// RecordTransport is an HTTP transport that saves every request and response
+// in a folder, one JSON file per interaction, with the secrets redacted.
+type RecordTransport struct {
+	Base http.RoundTripper
+	Dir  string
+
+	mu     sync.Mutex
+	serial int
+}
+
+// ReplayTransport is an HTTP transport that answers the requests with the
+// responses saved by RecordTransport, without using the network.
+type ReplayTransport struct {
+	Dir string
+
+	mu           sync.Mutex
+	interactions []*Interaction
+}
+
+// SetRecording saves the HTTP interactions in a folder, or answers them from
+// the interactions saved in a folder, depending on which one is not empty.
+func (cli *CLI) SetRecording(record string, replay string) error {
+	if record != "" && replay != "" {
+		return fmt.Errorf("cannot record and replay at the same time")
+	}
+
+	if record != "" {
+		transport, err := NewRecordTransport(http.DefaultTransport, record)
+
+		if err != nil {
+			return err
+		}
+
+		http.DefaultTransport = transport
+	}
+
+	if replay != "" {
+		transport, err := NewReplayTransport(replay)
+
+		if err != nil {
+			return err
+		}
+
+		http.DefaultTransport = transport
+	}
+
+	return nil
+}
+
+// NewRecordTransport returns a transport that saves the interactions in dir.
+// The numbering continues after the interactions already in the folder, so a
+// session with multiple commands can be recorded in the same place.
+func NewRecordTransport(base http.RoundTripper, dir string) (*RecordTransport, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordTransport{Base: base, Dir: dir, serial: len(files)}, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *RecordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody := req.Body
+	body, err := readBody(&reqBody)
+
+	if err != nil {
+		return nil, err
+	}
+
+	clone := req.Clone(req.Context())
+
+	if body != nil {
+		clone.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	res, err := t.Base.RoundTrip(clone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := readBody(&res.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var interaction Interaction
+
+	interaction.Request.Method = req.Method
+	interaction.Request.URL = redact(req.URL.String())
+	interaction.Request.Header = redactHeader(req.Header)
+	interaction.Request.Body, interaction.Request.BodyBase64 = encodeBody(body)
+	interaction.Response.StatusCode = res.StatusCode
+	interaction.Response.Header = redactHeader(res.Header)
+	interaction.Response.Body, interaction.Response.BodyBase64 = encodeBody(resBody)
+
+	out, err := json.MarshalIndent(interaction, "", "\x20\x20")
+
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.serial++
+	filename := filepath.Join(t.Dir, fmt.Sprintf("%04d-%s.json", t.serial, path.Base(req.URL.Path)))
+	t.mu.Unlock()
+
+	if err := os.WriteFile(filename, append(out, '\n'), 0600); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// NewReplayTransport loads the interactions saved in dir.
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no interactions recorded in %s", dir)
+	}
+
+	sort.Strings(files)
+
+	t := &ReplayTransport{Dir: dir}
+
+	for _, filename := range files {
+		var interaction Interaction
+
+		data, err := os.ReadFile(filename)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+
+		t.interactions = append(t.interactions, &interaction)
+	}
+
+	return t, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface. It returns the first
+// unused interaction for the same API method with the same request body, or
+// the first unused interaction for the same API method if none has the same
+// body, so the replay tolerates small changes like a different timestamp.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody := req.Body
+	body, err := readBody(&reqBody)
+
+	if err != nil {
+		return nil, err
+	}
+
+	method := path.Base(req.URL.Path)
+	encoded, _ := encodeBody(body)
+	encoded = redact(encoded)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var match *Interaction
+
+	for _, interaction := range t.interactions {
+		if interaction.used || path.Base(requestPath(interaction.Request.URL)) != method {
+			continue
+		}
+
+		if interaction.Request.Body == encoded {
+			match = interaction
+			break
+		}
+
+		if match == nil {
+			match = interaction
+		}
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("replay; no interaction recorded for %s in %s", method, t.Dir)
+	}
+
+	match.used = true
+
+	resBody := []byte(match.Response.Body)
+
+	if match.Response.BodyBase64 {
+		if resBody, err = base64.StdEncoding.DecodeString(match.Response.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	header := match.Response.Header
+
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.Response.StatusCode, http.StatusText(match.Response.StatusCode)),
+		StatusCode:    match.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(resBody)),
+		ContentLength: int64(len(resBody)),
+		Request:       req,
+	}, nil
+}
+
+// readBody reads a request or response body and replaces it with a copy, so
+// it can be read again by the caller.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	*body = io.NopCloser(bytes.NewReader(data))
+
+	return data, nil
+}
+
+// encodeBody returns the body as text, or as base64 if it is not valid UTF-8,
+// like a multipart form with a binary file. The secrets are redacted in both
+// cases, before the body is encoded.
+func encodeBody(body []byte) (string, bool) {
+	body = redactBytes(body)
+
+	if utf8.Valid(body) {
+		return string(body), false
+	}
+
+	return base64.StdEncoding.EncodeToString(body), true
+}
+
+func redact(text string) string {
+	return string(redactBytes([]byte(text)))
+}
+
+func redactBytes(data []byte) []byte {
+	return secretPattern.ReplaceAllFunc(data, func(secret []byte) []byte {
+		return append(secret[:5:5], "REDACTED"...)
+	})
+}
+
+func redactHeader(header http.Header) http.Header {
+	clone := http.Header{}
+
+	for key, values := range header {
+		for _, value := range values {
+			clone.Add(key, redact(value))
+		}
+	}
+
+	for _, key := range redactedHeaders {
+		if clone.Get(key) != "" {
+			clone.Set(key, "REDACTED")
+		}
+	}
+
+	return clone
+}
+
+// requestPath returns the path of a recorded URL.
+func requestPath(address string) string {
+	if i := strings.IndexAny(address, "?#"); i >= 0 {
+		address = address[:i]
+	}
+
+	return address
+}
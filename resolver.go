@@ -0,0 +1,383 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cixtor/slackapi"
+)
+
+// defaultCacheTTL is how long the list of channels and users is trusted before
+// it is downloaded again. Use cache_ttl in the configuration file to change it.
+const defaultCacheTTL = time.Hour
+
+var (
+	channelIDPattern = regexp.MustCompile(`^[CGD][A-Z0-9]{6,}$`)
+	userIDPattern    = regexp.MustCompile(`^[UW][A-Z0-9]{6,}$`)
+)
+
+// Cache holds a local copy of the channels and users of a workspace.
+type Cache struct {
+	Updated  time.Time      `json:"updated"`
+	Channels []CacheChannel `json:"channels"`
+	Users    []CacheUser    `json:"users"`
+}
+
+// CacheChannel defines a channel in the cache.
+type CacheChannel struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// CacheUser defines a user in the cache.
+type CacheUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	RealName    string `json:"real_name,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Email       string `json:"email,omitempty"`
+}
+
+// CachePath returns the location of the cache for the active profile.
+func (cli *CLI) CachePath() string {
+	dir := os.Getenv("XDG_CACHE_HOME")
+
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".cache")
+	}
+
+	return filepath.Join(dir, "slackcli", cli.profileKey()+".json")
+}
+
+// cacheTTL returns the lifetime of the cache set in the configuration file.
+func (cli *CLI) cacheTTL() time.Duration {
+	if cli.config == nil {
+		return defaultCacheTTL
+	}
+
+	if ttl, err := time.ParseDuration(cli.config.Get("", "cache_ttl")); err == nil {
+		return ttl
+	}
+
+	return defaultCacheTTL
+}
+
+// loadCache returns the cached channels and users, downloading them again if
+// the cache does not exist or it is older than the TTL.
+func (cli *CLI) loadCache() (*Cache, error) {
+	if cli.cache != nil {
+		return cli.cache, nil
+	}
+
+	if cache, err := cli.readCache(); err == nil && time.Since(cache.Updated) < cli.cacheTTL() {
+		cli.cache = cache
+		return cli.cache, nil
+	}
+
+	return cli.refreshCache()
+}
+
+// readCache returns the cached channels and users as they are saved, even if
+// they are older than the TTL.
+func (cli *CLI) readCache() (*Cache, error) {
+	var cache Cache
+
+	data, err := os.ReadFile(cli.CachePath())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, err
+	}
+
+	return &cache, nil
+}
+
+// refreshCache downloads the list of public and private channels and the
+// list of users, every page of them, and saves them.
+func (cli *CLI) refreshCache() (*Cache, error) {
+	cache := &Cache{Updated: time.Now()}
+
+	for cursor := ""; ; {
+		var channels struct {
+			slackapi.Response
+			Channels []CacheChannel `json:"channels"`
+			Metadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+
+		values := map[string]string{"types": "public_channel,private_channel", "limit": "1000"}
+
+		if cursor != "" {
+			values["cursor"] = cursor
+		}
+
+		res, err := cli.Invoke(http.MethodPost, "conversations.list", RawArgs{Values: values})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := decodeInto(res, &channels); err != nil {
+			return nil, err
+		}
+
+		cache.Channels = append(cache.Channels, channels.Channels...)
+
+		if cursor = channels.Metadata.NextCursor; cursor == "" {
+			break
+		}
+	}
+
+	for cursor := ""; ; {
+		var users struct {
+			slackapi.Response
+			Members []struct {
+				ID       string `json:"id"`
+				Name     string `json:"name"`
+				RealName string `json:"real_name"`
+				Profile  struct {
+					DisplayName string `json:"display_name"`
+					Email       string `json:"email"`
+				} `json:"profile"`
+			} `json:"members"`
+			Metadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+
+		values := map[string]string{"limit": "1000"}
+
+		if cursor != "" {
+			values["cursor"] = cursor
+		}
+
+		res, err := cli.Invoke(http.MethodPost, "users.list", RawArgs{Values: values})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := decodeInto(res, &users); err != nil {
+			return nil, err
+		}
+
+		for _, member := range users.Members {
+			cache.Users = append(cache.Users, CacheUser{
+				ID:          member.ID,
+				Name:        member.Name,
+				RealName:    member.RealName,
+				DisplayName: member.Profile.DisplayName,
+				Email:       member.Profile.Email,
+			})
+		}
+
+		if cursor = users.Metadata.NextCursor; cursor == "" {
+			break
+		}
+	}
+
+	data, err := json.Marshal(cache)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cli.CachePath()), 0700); err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(cli.CachePath(), data, 0600); err != nil {
+		return nil, err
+	}
+
+	cli.cache = cache
+
+	return cache, nil
+}
+
+// decodeInto converts a response from the web API service into another struct
+// and returns the error reported by the service, if any.
+func decodeInto(v interface{}, target interface{}) error {
+	out, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(out, target); err != nil {
+		return err
+	}
+
+	if res := ResponseOf(v); !res.Ok && res.Error != "" {
+		return fmt.Errorf("%s", res.Error)
+	}
+
+	return nil
+}
+
+// resolveParams converts the names in the channel and user parameters of a
+// command into IDs.
+func (cli *CLI) resolveParams(command Command) error {
+	for _, param := range command.Params {
+		value := cli.params[param.Name]
+
+		if (param.Resolve != ResolveChannel && param.Resolve != ResolveUser) || value == "" {
+			continue
+		}
+
+		items := []string{value}
+
+		if param.Type == TypeList {
+			items = strings.Split(value, ",")
+		}
+
+		for i, item := range items {
+			var err error
+
+			if param.Resolve == ResolveChannel {
+				items[i], err = cli.ResolveChannel(strings.TrimSpace(item))
+			} else {
+				items[i], err = cli.ResolveUser(strings.TrimSpace(item))
+			}
+
+			if err != nil {
+				e := ClassifyError(err)
+				e.Code = param.Name + ": " + e.Code
+				return e
+			}
+		}
+
+		cli.params[param.Name] = strings.Join(items, ",")
+	}
+
+	return nil
+}
+
+// ResolveChannel converts #name into a channel ID and @user into the ID of a
+// direct message with that user. Channel IDs are returned as they are, and
+// names without a prefix are resolved if they exist.
+func (cli *CLI) ResolveChannel(input string) (string, error) {
+	if input == "" || channelIDPattern.MatchString(input) {
+		return input, nil
+	}
+
+	if strings.HasPrefix(input, "@") {
+		user, err := cli.ResolveUser(input)
+
+		if err != nil {
+			return "", err
+		}
+
+		return cli.openDirectMessage(user)
+	}
+
+	name := strings.TrimPrefix(input, "#")
+	cache, err := cli.loadCache()
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, channel := range cache.Channels {
+		if channel.Name == name {
+			return channel.ID, nil
+		}
+	}
+
+	if id := cli.searchChannel(name); id != "" {
+		return id, nil
+	}
+
+	if strings.HasPrefix(input, "#") {
+		return "", NewError(ErrorNotFound, "channel %s does not exist", input)
+	}
+
+	return input, nil
+}
+
+// ResolveUser converts @name or an email address into a user ID. User IDs
+// are returned as they are, and names without a prefix are resolved if they
+// exist.
+func (cli *CLI) ResolveUser(input string) (string, error) {
+	if input == "" || userIDPattern.MatchString(input) {
+		return input, nil
+	}
+
+	name := strings.TrimPrefix(input, "@")
+	email := strings.Contains(name, "@")
+	cache, err := cli.loadCache()
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, user := range cache.Users {
+		if email && strings.EqualFold(user.Email, name) {
+			return user.ID, nil
+		}
+
+		if !email && (user.Name == name || user.DisplayName == name) {
+			return user.ID, nil
+		}
+	}
+
+	if email || strings.HasPrefix(input, "@") {
+		return "", NewError(ErrorNotFound, "user %s does not exist", input)
+	}
+
+	return input, nil
+}
+
+// searchChannel looks for channels that are not in the cache, like the ones
+// created after it was saved, the same way the conversations.id command does
+// it.
+func (cli *CLI) searchChannel(name string) string {
+	result := cli.api.SearchModules(slackapi.SearchModulesInput{
+		Module:  "channels",
+		Query:   name,
+		Count:   100,
+		Page:    1,
+		Sort:    "timestamp",
+		SortDir: "desc",
+	})
+
+	for _, item := range result.Items {
+		if item.Name == name {
+			return item.ID
+		}
+	}
+
+	return ""
+}
+
+// openDirectMessage returns the ID of the direct message with a user.
+func (cli *CLI) openDirectMessage(user string) (string, error) {
+	var res struct {
+		slackapi.Response
+		Channel struct {
+			ID string `json:"id"`
+		} `json:"channel"`
+	}
+
+	err := decodeInto(cli.api.ConversationsOpen(slackapi.ConversationsOpenInput{
+		ReturnIm: true,
+		Users:    user,
+	}), &res)
+
+	if err != nil {
+		return "", fmt.Errorf("conversations.open; %s", err)
+	}
+
+	return res.Channel.ID, nil
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Rate limit tiers of the web API service, expressed as the number of requests
+// per minute that a method accepts. Methods that are not listed in methodTiers
+// belong to Tier 3, which is the tier of most methods.
+const (
+	Tier1 = 1
+	Tier2 = 20
+	Tier3 = 50
+	Tier4 = 100
+	// TierPostMessage allows one message per second.
+	TierPostMessage = 60
+)
+
+// methodTiers maps API methods to their rate limit tier.
+var methodTiers = map[string]int{
+	"conversations.create":           Tier2,
+	"conversations.list":             Tier2,
+	"dnd.teamInfo":                   Tier2,
+	"emoji.list":                     Tier2,
+	"files.upload":                   Tier2,
+	"migration.exchange":             Tier2,
+	"reactions.list":                 Tier2,
+	"search.all":                     Tier2,
+	"search.files":                   Tier2,
+	"search.messages":                Tier2,
+	"stars.list":                     Tier2,
+	"team.accessLogs":                Tier2,
+	"team.billableInfo":              Tier2,
+	"team.integrationLogs":           Tier2,
+	"users.list":                     Tier2,
+	"users.setPhoto":                 Tier2,
+	"apps.event.authorizations.list": Tier4,
+	"auth.test":                      Tier4,
+	"bots.info":                      Tier4,
+	"dnd.info":                       Tier4,
+	"team.info":                      Tier4,
+	"users.getPresence":              Tier4,
+	"users.info":                     Tier4,
+	"users.lookupByEmail":            Tier4,
+	"users.profile.get":              Tier4,
+	"chat.meMessage":                 TierPostMessage,
+	"chat.postEphemeral":             TierPostMessage,
+	"chat.postMessage":               TierPostMessage,
+}
+
+// writeMethods are the methods that create something each time they are
+// called. A server error does not mean that the request was not processed, so
+// they are only retried when the request was rejected or never sent.
+var writeMethods = map[string]bool{
+	"apps.manifest.create": true,
+	"chat.meMessage":       true,
+	"chat.postEphemeral":   true,
+	"chat.postMessage":     true,
+	"chat.scheduleMessage": true,
+	"conversations.create": true,
+	"files.comments.add":   true,
+	"files.upload":         true,
+}
+
+// RetryTransport is an HTTP transport that spaces out the requests to each
+// method of the web API service according to its rate limit tier, and retries
+// the requests that fail because of rate limiting, a server error or a network
+// error that happened before the request reached the server. It waits as long
+// as the Retry-After header says, or an exponential backoff with jitter,
+// without exceeding the maximum number of retries and the total time allowed
+// for retries.
+type RetryTransport struct {
+	Base       http.RoundTripper
+	MaxRetries int
+	Timeout    time.Duration
+
+	mu      sync.Mutex
+	history map[string][]time.Time
+	random  *rand.Rand
+}
+
+// NewRetryTransport returns a transport that retries the requests sent by base.
+func NewRetryTransport(base http.RoundTripper, maxRetries int, timeout time.Duration) *RetryTransport {
+	return &RetryTransport{
+		Base:       base,
+		MaxRetries: maxRetries,
+		Timeout:    timeout,
+		history:    map[string][]time.Time{},
+		random:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		body = data
+	}
+
+	method := path.Base(req.URL.Path)
+	deadline := time.Now().Add(t.Timeout)
+
+	for attempt := 0; ; attempt++ {
+		if !t.wait(req, t.budgetDelay(method)) {
+			return nil, req.Context().Err()
+		}
+
+		clone := req.Clone(req.Context())
+
+		if body != nil {
+			clone.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		res, err := t.Base.RoundTrip(clone)
+
+		if attempt >= t.MaxRetries || !retryable(method, res, err) {
+			return res, err
+		}
+
+		delay := t.backoff(attempt, res)
+
+		if time.Now().Add(delay).After(deadline) {
+			return res, err
+		}
+
+		if res != nil {
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		if !t.wait(req, delay) {
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// retryable returns true if the request failed because of rate limiting, an
+// error in the server, or a network error that prevented the request from
+// reaching the server. Other network errors, like a connection closed while
+// waiting for the response, are not retried because the server may have
+// processed the request, and sending a message or uploading a file twice is
+// worse than reporting the error. For the same reason, server errors are not
+// retried for the methods in writeMethods.
+func retryable(method string, res *http.Response, err error) bool {
+	if err != nil {
+		return notSent(err)
+	}
+
+	if res.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return res.StatusCode >= 500 && !writeMethods[method]
+}
+
+// notSent returns true if the error happened while connecting to the server,
+// before any part of the request was sent.
+func notSent(err error) bool {
+	var opErr *net.OpError
+
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// backoff returns the time to wait before the next attempt. The web API
+// service sends a Retry-After header with the number of seconds to wait when
+// it rejects a request because of rate limiting. Otherwise, the delay doubles
+// on each attempt, up to 30 seconds, and a random part is added to avoid that
+// concurrent clients retry at the same time.
+func (t *RetryTransport) backoff(attempt int, res *http.Response) time.Duration {
+	if res != nil {
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	delay := time.Second << uint(attempt)
+
+	if delay > 30*time.Second || delay <= 0 {
+		delay = 30 * time.Second
+	}
+
+	t.mu.Lock()
+	jitter := time.Duration(t.random.Int63n(int64(delay)/2 + 1))
+	t.mu.Unlock()
+
+	return delay/2 + jitter
+}
+
+// budgetDelay records a request to a method and returns the time to wait in
+// order to send it without exceeding the number of requests per minute that
+// the rate limit tier of the method allows.
+func (t *RetryTransport) budgetDelay(method string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limit, ok := methodTiers[method]
+
+	if !ok {
+		limit = Tier3
+	}
+
+	now := time.Now()
+	recent := t.history[method][:0]
+
+	for _, sent := range t.history[method] {
+		if now.Sub(sent) < time.Minute {
+			recent = append(recent, sent)
+		}
+	}
+
+	var delay time.Duration
+
+	if len(recent) >= limit {
+		delay = recent[len(recent)-limit].Add(time.Minute).Sub(now)
+	}
+
+	t.history[method] = append(recent, now.Add(delay))
+
+	return delay
+}
+
+// wait sleeps for the given duration unless the request is cancelled. It
+// returns false if the request was cancelled.
+func (t *RetryTransport) wait(req *http.Request, delay time.Duration) bool {
+	if delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-req.Context().Done():
+		return false
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// roundTripperFunc implements http.RoundTripper with a function.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// retryServer answers each request with the status returned by status, which
+// receives the number of the attempt, starting at one.
+func retryServer(t *testing.T, header http.Header, status func(attempt int32) int) (*httptest.Server, *int32) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := status(atomic.AddInt32(&attempts, 1))
+
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+
+		w.WriteHeader(code)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server, &attempts
+}
+
+func postMethod(t *testing.T, transport http.RoundTripper, server *httptest.Server, method string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api/"+method, strings.NewReader("channel=C0123"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return transport.RoundTrip(req)
+}
+
+func TestRetryTooManyRequests(t *testing.T) {
+	header := http.Header{"Retry-After": {"0"}}
+	server, attempts := retryServer(t, header, func(attempt int32) int {
+		if attempt < 3 {
+			return http.StatusTooManyRequests
+		}
+
+		return http.StatusOK
+	})
+
+	transport := NewRetryTransport(http.DefaultTransport, 3, time.Minute)
+	res, err := postMethod(t, transport, server, "chat.postMessage")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", res.StatusCode, http.StatusOK)
+	}
+
+	if n := atomic.LoadInt32(attempts); n != 3 {
+		t.Fatalf("attempts = %d, want 3", n)
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	transport := NewRetryTransport(http.DefaultTransport, 3, time.Minute)
+	res := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"7"}}}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		if delay := transport.backoff(attempt, res); delay != 7*time.Second {
+			t.Fatalf("backoff(%d) = %s, want 7s", attempt, delay)
+		}
+	}
+}
+
+func TestRetryServerErrorBackoff(t *testing.T) {
+	transport := NewRetryTransport(http.DefaultTransport, 10, time.Minute)
+	res := &http.Response{StatusCode: http.StatusBadGateway, Header: http.Header{}}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		base := time.Second << uint(attempt)
+
+		if base > 30*time.Second {
+			base = 30 * time.Second
+		}
+
+		for i := 0; i < 20; i++ {
+			delay := transport.backoff(attempt, res)
+
+			if delay < base/2 || delay > base {
+				t.Fatalf("backoff(%d) = %s, want between %s and %s", attempt, delay, base/2, base)
+			}
+		}
+	}
+}
+
+func TestRetryServerError(t *testing.T) {
+	server, attempts := retryServer(t, nil, func(attempt int32) int {
+		if attempt == 1 {
+			return http.StatusServiceUnavailable
+		}
+
+		return http.StatusOK
+	})
+
+	transport := NewRetryTransport(http.DefaultTransport, 3, time.Minute)
+	res, err := postMethod(t, transport, server, "users.info")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	if n := atomic.LoadInt32(attempts); res.StatusCode != http.StatusOK || n != 2 {
+		t.Fatalf("status = %d after %d attempts, want %d after 2", res.StatusCode, n, http.StatusOK)
+	}
+}
+
+func TestRetryServerErrorWrite(t *testing.T) {
+	server, attempts := retryServer(t, nil, func(attempt int32) int {
+		return http.StatusServiceUnavailable
+	})
+
+	transport := NewRetryTransport(http.DefaultTransport, 3, time.Minute)
+	res, err := postMethod(t, transport, server, "chat.postMessage")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	if n := atomic.LoadInt32(attempts); res.StatusCode != http.StatusServiceUnavailable || n != 1 {
+		t.Fatalf("status = %d after %d attempts, want %d after 1, the message may have been posted", res.StatusCode, n, http.StatusServiceUnavailable)
+	}
+}
+
+func TestRetryMaxRetries(t *testing.T) {
+	header := http.Header{"Retry-After": {"0"}}
+	server, attempts := retryServer(t, header, func(attempt int32) int {
+		return http.StatusTooManyRequests
+	})
+
+	transport := NewRetryTransport(http.DefaultTransport, 2, time.Minute)
+	res, err := postMethod(t, transport, server, "users.info")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", res.StatusCode, http.StatusTooManyRequests)
+	}
+
+	if n := atomic.LoadInt32(attempts); n != 3 {
+		t.Fatalf("attempts = %d, want 3", n)
+	}
+}
+
+func TestRetryTimeout(t *testing.T) {
+	header := http.Header{"Retry-After": {"30"}}
+	server, attempts := retryServer(t, header, func(attempt int32) int {
+		return http.StatusTooManyRequests
+	})
+
+	transport := NewRetryTransport(http.DefaultTransport, 3, time.Second)
+	started := time.Now()
+	res, err := postMethod(t, transport, server, "users.info")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("waited %s, more than the timeout allows", elapsed)
+	}
+
+	if n := atomic.LoadInt32(attempts); res.StatusCode != http.StatusTooManyRequests || n != 1 {
+		t.Fatalf("status = %d after %d attempts, want %d after 1", res.StatusCode, n, http.StatusTooManyRequests)
+	}
+}
+
+func TestRetryContextCancelled(t *testing.T) {
+	header := http.Header{"Retry-After": {"30"}}
+	server, attempts := retryServer(t, header, func(attempt int32) int {
+		return http.StatusTooManyRequests
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL+"/api/users.info", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport := NewRetryTransport(http.DefaultTransport, 3, time.Minute)
+	started := time.Now()
+
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("waited %s after the request was cancelled", elapsed)
+	}
+
+	if n := atomic.LoadInt32(attempts); n != 1 {
+		t.Fatalf("attempts = %d, want 1", n)
+	}
+}
+
+func TestRetryConnectionRefused(t *testing.T) {
+	var attempts int32
+
+	base := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+		}
+
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+	})
+
+	req, err := http.NewRequest(http.MethodPost, "https://slack.com/api/chat.postMessage", strings.NewReader("text=hello"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := NewRetryTransport(base, 1, time.Minute).RoundTrip(req)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := atomic.LoadInt32(&attempts); res.StatusCode != http.StatusOK || n != 2 {
+		t.Fatalf("status = %d after %d attempts, want %d after 2", res.StatusCode, n, http.StatusOK)
+	}
+}
+
+func TestRetryLostResponse(t *testing.T) {
+	var attempts int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+
+		if err == nil {
+			conn.Close()
+		}
+	}))
+
+	defer server.Close()
+
+	transport := NewRetryTransport(http.DefaultTransport, 3, time.Minute)
+
+	if _, err := postMethod(t, transport, server, "chat.postMessage"); err == nil {
+		t.Fatal("expected an error when the connection is closed without a response")
+	}
+
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Fatalf("attempts = %d, want 1, the message may have been posted", n)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		res    *http.Response
+		err    error
+		want   bool
+	}{
+		{"ok", "users.info", &http.Response{StatusCode: http.StatusOK}, nil, false},
+		{"rate limited", "users.info", &http.Response{StatusCode: http.StatusTooManyRequests}, nil, true},
+		{"server error", "users.info", &http.Response{StatusCode: http.StatusInternalServerError}, nil, true},
+		{"not found", "users.info", &http.Response{StatusCode: http.StatusNotFound}, nil, false},
+		{"dial", "users.info", nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route to host")}, true},
+		{"refused", "users.info", nil, syscall.ECONNREFUSED, true},
+		{"read", "users.info", nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, false},
+		{"eof", "users.info", nil, errors.New("EOF"), false},
+		{"write rate limited", "chat.postMessage", &http.Response{StatusCode: http.StatusTooManyRequests}, nil, true},
+		{"write server error", "chat.postMessage", &http.Response{StatusCode: http.StatusBadGateway}, nil, false},
+		{"write upload error", "files.upload", &http.Response{StatusCode: http.StatusInternalServerError}, nil, false},
+		{"write dial", "chat.scheduleMessage", nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route to host")}, true},
+		{"write read", "chat.postMessage", nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, false},
+	}
+
+	for _, test := range tests {
+		if got := retryable(test.method, test.res, test.err); got != test.want {
+			t.Errorf("%s: retryable = %t, want %t", test.name, got, test.want)
+		}
+	}
+}
+
+func TestBudgetDelay(t *testing.T) {
+	transport := NewRetryTransport(http.DefaultTransport, 3, time.Minute)
+
+	for i := 0; i < Tier2; i++ {
+		if delay := transport.budgetDelay("users.list"); delay != 0 {
+			t.Fatalf("request %d to a Tier 2 method waits %s, want 0", i+1, delay)
+		}
+	}
+
+	if delay := transport.budgetDelay("users.list"); delay < 59*time.Second || delay > time.Minute {
+		t.Fatalf("request %d to a Tier 2 method waits %s, want about a minute", Tier2+1, delay)
+	}
+
+	for i := 0; i < Tier3; i++ {
+		if delay := transport.budgetDelay("conversations.info"); delay != 0 {
+			t.Fatalf("request %d to a Tier 3 method waits %s, want 0", i+1, delay)
+		}
+	}
+
+	if delay := transport.budgetDelay("conversations.info"); delay <= 0 {
+		t.Fatalf("request %d to a Tier 3 method does not wait", Tier3+1)
+	}
+}
+
+func TestBudgetDelayPostMessage(t *testing.T) {
+	transport := NewRetryTransport(http.DefaultTransport, 3, time.Minute)
+	now := time.Now()
+
+	for i := 0; i < TierPostMessage; i++ {
+		transport.history["chat.postMessage"] = append(transport.history["chat.postMessage"], now.Add(-time.Duration(TierPostMessage-i)*time.Second/2))
+	}
+
+	delay := transport.budgetDelay("chat.postMessage")
+	oldest := now.Add(-time.Duration(TierPostMessage) * time.Second / 2)
+	want := oldest.Add(time.Minute).Sub(now)
+
+	if delay < want-time.Second || delay > want {
+		t.Fatalf("delay = %s, want about %s", delay, want)
+	}
+
+	if delay := transport.budgetDelay("users.info"); delay != 0 {
+		t.Fatalf("other methods wait %s, want 0", delay)
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	// The time zones of the users are loaded on systems without a zoneinfo
+	// database too, like minimal containers and Windows.
+	_ "time/tzdata"
+)
+
+// maxScheduleAhead is how far in the future a message can be scheduled.
+const maxScheduleAhead = 120 * 24 * time.Hour
+
+// defaultPostHour is the hour of the day used when a scheduled message has a
+// day but no time, like "tomorrow" or "2024-05-01".
+const defaultPostHour = 9
+
+// minUnixDigits is the length of the shortest Unix time accepted, so a bare
+// hour like 17 is read as a time of the day rather than a Unix time.
+const minUnixDigits = 9
+
+// dateLayouts are the formats accepted for a date and time, in the time zone
+// of the user. The input is in lowercase when it is compared.
+var dateLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02t15:04:05",
+	"2006-01-02t15:04",
+	"2006-01-02",
+}
+
+// clockLayouts are the formats accepted for the time of the day.
+var clockLayouts = []string{"15:04", "3:04pm", "3pm", "15"}
+
+// ParsePostTime converts the time of a scheduled message into an instant. It
+// accepts a Unix time, a time from now like +2h, +90m or +1d, a date and time
+// like 2024-05-01 09:30 or RFC 3339, or a phrase like "tomorrow 9:00",
+// "friday 5pm", "17:30" or "17", which is today or tomorrow, whichever comes
+// first. Dates and clock times are in the time zone returned by location,
+// which is only called for them.
+func ParsePostTime(input string, now time.Time, location func() (*time.Location, error)) (time.Time, error) {
+	text := strings.ToLower(strings.Join(strings.Fields(input), "\x20"))
+
+	if text == "" {
+		return time.Time{}, UsageError("post_at: missing time")
+	}
+
+	when, err := parsePostTime(text, now, location)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !when.After(now) {
+		return time.Time{}, UsageError("post_at: %s is in the past", when.Format(time.RFC3339))
+	}
+
+	if when.Sub(now) > maxScheduleAhead {
+		return time.Time{}, UsageError("post_at: %s is more than 120 days ahead", when.Format(time.RFC3339))
+	}
+
+	return when, nil
+}
+
+func parsePostTime(text string, now time.Time, location func() (*time.Location, error)) (time.Time, error) {
+	if len(text) >= minUnixDigits {
+		if unix, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return time.Unix(unix, 0), nil
+		}
+	}
+
+	if strings.HasPrefix(text, "+") {
+		offset, err := parseOffset(text[1:])
+
+		if err != nil {
+			return time.Time{}, UsageError("post_at: %q is not a duration, use something like +30m, +2h or +1d", text)
+		}
+
+		return now.Add(offset), nil
+	}
+
+	if when, err := time.Parse(time.RFC3339, strings.ToUpper(text)); err == nil {
+		return when, nil
+	}
+
+	loc, err := location()
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, layout := range dateLayouts {
+		if when, err := time.ParseInLocation(layout, text, loc); err == nil {
+			if layout == "2006-01-02" {
+				year, month, day := when.Date()
+				when = time.Date(year, month, day, defaultPostHour, 0, 0, 0, loc)
+			}
+
+			return when, nil
+		}
+	}
+
+	if when, ok := parsePhrase(text, now.In(loc)); ok {
+		return when, nil
+	}
+
+	return time.Time{}, UsageError("post_at: %q is not a time, use a Unix time, +2h, \"2024-05-01 09:30\" or \"tomorrow 9:00\"", text)
+}
+
+// parseOffset reads a duration like 90m or 2h30m, with d for days.
+func parseOffset(text string) (time.Duration, error) {
+	if days := strings.TrimSuffix(text, "d"); days != text {
+		number, err := strconv.Atoi(days)
+
+		if err != nil {
+			return 0, err
+		}
+
+		return time.Duration(number) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(text)
+}
+
+// parsePhrase reads a day, like today, tomorrow or a weekday, followed by a
+// time of the day, either of them optional. The day defaults to the next time
+// the clock shows the time, and the time defaults to 9:00.
+func parsePhrase(text string, now time.Time) (time.Time, bool) {
+	words := strings.Fields(text)
+	hasDay := false
+	days := 0
+
+	if len(words) > 0 {
+		if ahead, ok := daysAhead(words[0], now.Weekday()); ok {
+			hasDay = true
+			days = ahead
+			words = words[1:]
+		}
+	}
+
+	if len(words) > 0 && words[0] == "at" {
+		words = words[1:]
+	}
+
+	clock := time.Date(0, 1, 1, defaultPostHour, 0, 0, 0, time.UTC)
+
+	if len(words) > 0 {
+		var ok bool
+
+		if clock, ok = parseClock(strings.Join(words, "")); !ok {
+			return time.Time{}, false
+		}
+	} else if !hasDay {
+		return time.Time{}, false
+	}
+
+	year, month, day := now.Date()
+	when := time.Date(year, month, day+days, clock.Hour(), clock.Minute(), 0, 0, now.Location())
+
+	if !hasDay && !when.After(now) {
+		when = when.AddDate(0, 0, 1)
+	}
+
+	return when, true
+}
+
+// daysAhead returns the number of days until the given day, which is today,
+// tomorrow or the next day with the name of a weekday.
+func daysAhead(word string, today time.Weekday) (int, bool) {
+	switch word {
+	case "today":
+		return 0, true
+	case "tomorrow":
+		return 1, true
+	}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+
+		if word == name || word == name[:3] {
+			ahead := (int(day) - int(today) + 7) % 7
+
+			if ahead == 0 {
+				ahead = 7
+			}
+
+			return ahead, true
+		}
+	}
+
+	return 0, false
+}
+
+// parseClock reads a time of the day like 17:30, 5:30pm or 5pm.
+func parseClock(text string) (time.Time, bool) {
+	for _, layout := range clockLayouts {
+		if clock, err := time.Parse(layout, text); err == nil {
+			return clock, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// userLocation returns the time zone of the authenticated user, from the tz
+// field of users.info.
+func (cli *CLI) userLocation() (*time.Location, error) {
+	auth, err := cli.Invoke(http.MethodPost, "auth.test", RawArgs{})
+
+	if err != nil {
+		return nil, fmt.Errorf("auth.test; %s", err)
+	}
+
+	if ok, _ := auth["ok"].(bool); !ok {
+		return nil, fmt.Errorf("auth.test; %v", auth["error"])
+	}
+
+	userID, _ := auth["user_id"].(string)
+
+	info, err := cli.Invoke(http.MethodPost, "users.info", RawArgs{Values: map[string]string{"user": userID}})
+
+	if err != nil {
+		return nil, fmt.Errorf("users.info; %s", err)
+	}
+
+	if ok, _ := info["ok"].(bool); !ok {
+		return nil, fmt.Errorf("users.info; %v", info["error"])
+	}
+
+	user, _ := info["user"].(map[string]interface{})
+	tz, _ := user["tz"].(string)
+
+	if tz == "" {
+		return time.Local, nil
+	}
+
+	return time.LoadLocation(tz)
+}
@@ -0,0 +1,511 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// historySize is the number of lines kept in the history of the shell.
+const historySize = 1000
+
+// shellBuiltins are the commands implemented by the shell itself.
+var shellBuiltins = []string{"exit", "quit", "use"}
+
+// lineEditor reads the lines typed in the shell from a terminal in
+// non-canonical mode, to offer tab completion and to browse the history with
+// the arrow keys.
+type lineEditor struct {
+	input    *bufio.Reader
+	output   io.Writer
+	history  []string
+	complete func(line string) (string, []string)
+}
+
+// CallShell starts an interactive session where the commands are typed
+// without the name of the program. The session keeps the credentials, the
+// cache and the options of the program between commands. Use "use #name" to
+// set the default channel of the following commands, and "exit" to quit. The
+// commands cannot read stdin with "-", because the shell reads from it. The
+// line editor is only used when the standard input is a terminal, otherwise
+// the lines are read as they come, like in a script.
+func (cli *CLI) CallShell() int {
+	history := loadHistory(historyPath())
+
+	editor := &lineEditor{
+		input:    bufio.NewReader(cli.stdin),
+		output:   cli.stderr,
+		history:  history,
+		complete: cli.completeLine,
+	}
+
+	interactive := cli.stdin == os.Stdin && isTerminal(os.Stdin) && stty(os.Stdin, "-icanon", "-echo", "-isig", "min", "1") == nil
+
+	cli.stdin = nil
+
+	if interactive {
+		defer stty(os.Stdin, "icanon", "echo", "isig")
+	}
+
+	exitCode := ExitSuccess
+
+	for {
+		var line string
+		var err error
+
+		if interactive {
+			line, err = editor.readLine(cli.prompt())
+		} else {
+			line, err = editor.input.ReadString('\n')
+		}
+
+		if err != nil && (err != io.EOF || line == "") {
+			break
+		}
+
+		line = strings.TrimSpace(line)
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if interactive {
+			editor.remember(line)
+			stty(os.Stdin, "icanon", "echo", "isig")
+		}
+
+		code, quit := cli.runShellLine(line)
+		exitCode = code
+
+		if interactive {
+			stty(os.Stdin, "-icanon", "-echo", "-isig", "min", "1")
+		}
+
+		if quit {
+			break
+		}
+	}
+
+	if interactive {
+		if err := saveHistory(historyPath(), editor.history); err != nil {
+			cli.command = "shell"
+			cli.PrintError(fmt.Errorf("shell; history; %s", err))
+		}
+	}
+
+	return exitCode
+}
+
+// runShellLine executes one line of the shell. It returns the exit code of
+// the command and whether the session is over.
+func (cli *CLI) runShellLine(line string) (int, bool) {
+	cli.command = "shell"
+
+	args, err := splitWords(line)
+
+	if err != nil {
+		return cli.PrintError(UsageError("shell; %s", err)), false
+	}
+
+	cli.command = args[0]
+
+	switch args[0] {
+	case "exit", "quit":
+		return ExitSuccess, true
+
+	case "use":
+		return cli.useChannel(args[1:]), false
+
+	case "shell":
+		return cli.PrintError(UsageError("shell; the shell is already running")), false
+	}
+
+	return cli.Execute(args), false
+}
+
+// useChannel sets the channel used by the following commands that expect a
+// channel and did not receive one. Without arguments it prints the current
+// channel, and with a dash it forgets it.
+func (cli *CLI) useChannel(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(cli.stdout, cli.profile.Channel)
+		return ExitSuccess
+	}
+
+	if args[0] == "-" {
+		cli.profile.Channel = ""
+		return ExitSuccess
+	}
+
+	if _, err := cli.ResolveChannel(args[0]); err != nil {
+		e := ClassifyError(err)
+		e.Code = "use; " + e.Code
+		return cli.PrintError(e)
+	}
+
+	cli.profile.Channel = args[0]
+
+	return ExitSuccess
+}
+
+// prompt returns the text printed before each line of the shell.
+func (cli *CLI) prompt() string {
+	if cli.profile.Channel != "" {
+		return "slackcli " + cli.profile.Channel + "> "
+	}
+
+	return "slackcli> "
+}
+
+// completeLine completes the last word of a line. It returns the line with
+// the completed word, and the candidates if there is more than one.
+func (cli *CLI) completeLine(line string) (string, []string) {
+	words := strings.Fields(line)
+
+	if len(words) == 0 || strings.HasSuffix(line, "\x20") {
+		words = append(words, "")
+	}
+
+	word := words[len(words)-1]
+	head := line[:len(line)-len(word)]
+
+	var candidates []string
+
+	for _, candidate := range cli.completions(words) {
+		if strings.HasPrefix(candidate, word) {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return line, nil
+	}
+
+	if len(candidates) == 1 {
+		if strings.HasSuffix(candidates[0], "=") {
+			return head + candidates[0], nil
+		}
+
+		return head + candidates[0] + "\x20", nil
+	}
+
+	sort.Strings(candidates)
+
+	return head + commonPrefix(candidates), candidates
+}
+
+// completions returns the possible values of the last word of a line. The
+// first word is a command, the rest are parameters of that command: flags,
+// enum values, channel names and user names from the cache.
+func (cli *CLI) completions(words []string) []string {
+	var out []string
+
+	word := words[len(words)-1]
+
+	if len(words) == 1 {
+		out = append(out, shellBuiltins...)
+
+		for _, command := range cli.commands {
+			out = append(out, command.Name)
+		}
+
+		for name := range cli.Aliases() {
+			out = append(out, name)
+		}
+
+		sort.Strings(out)
+
+		return out
+	}
+
+	if words[0] == "use" || strings.HasPrefix(word, "#") {
+		return cli.channelNames()
+	}
+
+	if strings.HasPrefix(word, "@") {
+		return cli.userNames()
+	}
+
+	params := cli.commandParams(words[0])
+
+	if strings.HasPrefix(word, "-") {
+		for _, param := range params {
+			if eq := strings.Index(word, "="); eq > 0 && strings.TrimLeft(word[:eq], "-") == param.Name {
+				for _, value := range cli.paramCompletions(param) {
+					out = append(out, word[:eq+1]+value)
+				}
+
+				continue
+			}
+
+			if param.Type == TypeBool {
+				out = append(out, "--"+param.Name)
+			} else {
+				out = append(out, "--"+param.Name+"=")
+			}
+		}
+
+		return out
+	}
+
+	position := 0
+
+	for _, arg := range words[1 : len(words)-1] {
+		if !strings.HasPrefix(arg, "-") {
+			position++
+		}
+	}
+
+	for _, param := range params {
+		if position == 0 || param.Type == TypeRest {
+			return cli.paramCompletions(param)
+		}
+
+		position--
+	}
+
+	return nil
+}
+
+// commandParams returns the parameters of a command, an alias or a prefix.
+func (cli *CLI) commandParams(name string) []Param {
+	args, err := cli.expandAlias([]string{name})
+
+	if err != nil {
+		return nil
+	}
+
+	command, err := cli.LookupCommand(args[0])
+
+	if err != nil {
+		return nil
+	}
+
+	return command.Params
+}
+
+// paramCompletions returns the values suggested for a parameter.
+func (cli *CLI) paramCompletions(param Param) []string {
+	switch {
+	case len(param.Values) > 0:
+		return param.Values
+
+	case param.Resolve == ResolveChannel:
+		return cli.channelNames()
+
+	case param.Resolve == ResolveUser:
+		return cli.userNames()
+
+	case param.Type == TypeBool:
+		return []string{"true", "false"}
+	}
+
+	return nil
+}
+
+// channelNames returns the channels in the cache as #name.
+func (cli *CLI) channelNames() []string {
+	var out []string
+
+	cache, err := cli.loadCache()
+
+	if err != nil {
+		return nil
+	}
+
+	for _, channel := range cache.Channels {
+		out = append(out, "#"+channel.Name)
+	}
+
+	return out
+}
+
+// userNames returns the users in the cache as @name.
+func (cli *CLI) userNames() []string {
+	var out []string
+
+	cache, err := cli.loadCache()
+
+	if err != nil {
+		return nil
+	}
+
+	for _, user := range cache.Users {
+		out = append(out, "@"+user.Name)
+	}
+
+	return out
+}
+
+// commonPrefix returns the longest prefix shared by all the words.
+func commonPrefix(words []string) string {
+	prefix := words[0]
+
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	return prefix
+}
+
+// readLine prints the prompt and reads one line. Tab completes the last word,
+// or lists the candidates when there is more than one; the up and down keys
+// browse the history; Ctrl+C discards the line; Ctrl+U deletes it; and Ctrl+D
+// ends the session when the line is empty.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	var line []rune
+
+	position := len(e.history)
+	fmt.Fprint(e.output, prompt)
+
+	redraw := func() {
+		fmt.Fprintf(e.output, "\r\x1b[K%s%s", prompt, string(line))
+	}
+
+	for {
+		char, _, err := e.input.ReadRune()
+
+		if err != nil {
+			return string(line), err
+		}
+
+		switch char {
+		case '\r', '\n':
+			fmt.Fprintln(e.output)
+			return string(line), nil
+
+		case 0x03: // Ctrl+C
+			fmt.Fprintln(e.output, "^C")
+			line = nil
+			position = len(e.history)
+			fmt.Fprint(e.output, prompt)
+
+		case 0x04: // Ctrl+D
+			if len(line) == 0 {
+				fmt.Fprintln(e.output)
+				return "", io.EOF
+			}
+
+		case 0x15: // Ctrl+U
+			line = nil
+			redraw()
+
+		case 0x7f, 0x08: // Backspace
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				redraw()
+			}
+
+		case '\t':
+			completed, candidates := e.complete(string(line))
+
+			if len(candidates) > 0 && completed == string(line) {
+				fmt.Fprintf(e.output, "\r\n%s\r\n", strings.Join(candidates, "\x20\x20"))
+			}
+
+			line = []rune(completed)
+			redraw()
+
+		case 0x1b: // escape sequence of the arrow keys
+			if next, _ := e.input.ReadByte(); next != '[' {
+				continue
+			}
+
+			key, _ := e.input.ReadByte()
+
+			if key == 'A' && position > 0 {
+				position--
+				line = []rune(e.history[position])
+				redraw()
+			}
+
+			if key == 'B' && position < len(e.history) {
+				position++
+				line = nil
+
+				if position < len(e.history) {
+					line = []rune(e.history[position])
+				}
+
+				redraw()
+			}
+
+		default:
+			if char >= '\x20' {
+				line = append(line, char)
+				fmt.Fprint(e.output, string(char))
+			}
+		}
+	}
+}
+
+// remember adds a line to the history, unless it repeats the previous one.
+func (e *lineEditor) remember(line string) {
+	if n := len(e.history); n > 0 && e.history[n-1] == line {
+		return
+	}
+
+	e.history = append(e.history, line)
+}
+
+// historyPath returns the location of the history of the shell.
+func historyPath() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(dir, "slackcli", "history")
+}
+
+// loadHistory reads the history of the shell, one line per command.
+func loadHistory(filename string) []string {
+	var history []string
+
+	data, err := os.ReadFile(filename)
+
+	if err != nil {
+		return nil
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			history = append(history, line)
+		}
+	}
+
+	return history
+}
+
+// saveHistory writes the last lines of the history of the shell. Lines with
+// a token or a cookie, like auth.login xoxp-..., are left out so the secrets
+// do not end up on disk; they are only available during the session.
+func saveHistory(filename string, history []string) error {
+	var lines []string
+
+	for _, line := range history {
+		if !secretPattern.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+
+	history = lines
+
+	if len(history) > historySize {
+		history = history[len(history)-historySize:]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, []byte(strings.Join(history, "\n")+"\n"), 0600)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// SetTemplate compiles the Go template used to print successful responses,
+// either from the text passed in the command line or from a file. The helper
+// functions are described in templateFuncs.
+func (cli *CLI) SetTemplate(text string, filename string) error {
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+
+		if err != nil {
+			return err
+		}
+
+		text = string(data)
+	}
+
+	if text == "" {
+		return nil
+	}
+
+	tmpl, err := template.New("output").Funcs(cli.templateFuncs()).Parse(text)
+
+	if err != nil {
+		return err
+	}
+
+	cli.template = tmpl
+
+	return nil
+}
+
+// templateFuncs returns the functions available in the output templates.
+//
+//	ts VALUE            converts a Slack timestamp like 1650000000.123456 to a time
+//	date LAYOUT VALUE   formats a Slack timestamp using a Go time layout
+//	mention USER        resolves a user ID to @username, using the cache of users
+//	pad WIDTH VALUE     adds spaces to the right until the text has WIDTH runes
+//	padleft WIDTH VALUE adds spaces to the left until the text has WIDTH runes
+//	join SEP LIST       joins the items of a list with a separator
+//	json VALUE          encodes a value as compact JSON
+func (cli *CLI) templateFuncs() template.FuncMap {
+	mentions := map[string]string{}
+
+	return template.FuncMap{
+		"ts": slackTime,
+		"date": func(layout string, v interface{}) string {
+			return slackTime(v).Format(layout)
+		},
+		"mention": func(user string) string {
+			if name, ok := mentions[user]; ok {
+				return name
+			}
+
+			mentions[user] = "@" + cli.userName(user)
+
+			return mentions[user]
+		},
+		"pad": func(width int, v interface{}) string {
+			text := fmt.Sprint(v)
+			return text + strings.Repeat("\x20", max(0, width-utf8.RuneCountInString(text)))
+		},
+		"padleft": func(width int, v interface{}) string {
+			text := fmt.Sprint(v)
+			return strings.Repeat("\x20", max(0, width-utf8.RuneCountInString(text))) + text
+		},
+		"join": func(sep string, v interface{}) string {
+			var items []string
+
+			list := reflect.ValueOf(v)
+
+			if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+				return fmt.Sprint(v)
+			}
+
+			for i := 0; i < list.Len(); i++ {
+				items = append(items, fmt.Sprint(list.Index(i).Interface()))
+			}
+
+			return strings.Join(items, sep)
+		},
+		"json": func(v interface{}) (string, error) {
+			out, err := json.Marshal(v)
+			return string(out), err
+		},
+	}
+}
+
+// printTemplate renders the response with the template chosen by the user.
+// The template receives the JSON document, after the query if there is one,
+// so the fields are accessed by their JSON names, like .members or .user.id,
+// in every command and with or without --all.
+func (cli *CLI) printTemplate(out []byte) int {
+	var buf bytes.Buffer
+	var doc interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&doc); err != nil {
+		return cli.PrintError(fmt.Errorf("template; %s", err))
+	}
+
+	if err := cli.template.Execute(&buf, doc); err != nil {
+		return cli.PrintError(fmt.Errorf("template; %s", err))
+	}
+
+	cli.stdout.Write(buf.Bytes())
+
+	return 0
+}
+
+// userName returns the username associated to a user ID, from the cache of
+// users if possible, or the ID itself if the user cannot be found.
+func (cli *CLI) userName(user string) string {
+	if cache, err := cli.loadCache(); err == nil {
+		for _, item := range cache.Users {
+			if item.ID == user {
+				return item.Name
+			}
+		}
+	}
+
+	page, err := decodePage(cli.api.UsersInfo(user))
+
+	if err != nil {
+		return user
+	}
+
+	if name, ok := lookupField(page, "user.name").(string); ok && name != "" {
+		return name
+	}
+
+	return user
+}
+
+// slackTime converts a Slack timestamp, which is the number of seconds since
+// the Unix epoch with a fractional part that makes it unique, into a time.
+func slackTime(v interface{}) time.Time {
+	var seconds float64
+
+	switch value := v.(type) {
+	case string:
+		seconds, _ = strconv.ParseFloat(value, 64)
+	case json.Number:
+		seconds, _ = value.Float64()
+	case float64:
+		seconds = value
+	case int:
+		seconds = float64(value)
+	case int64:
+		seconds = float64(value)
+	default:
+		seconds, _ = strconv.ParseFloat(fmt.Sprint(v), 64)
+	}
+
+	whole := int64(seconds)
+
+	return time.Unix(whole, int64((seconds-float64(whole))*1e9)).Round(time.Microsecond)
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}